@@ -7,12 +7,17 @@ import (
 	"io"
 	"os"
 
+	"github.com/tuanvm-tyson/ddtrace/internal/cache"
 	"github.com/tuanvm-tyson/ddtrace/internal/cli"
 	"github.com/tuanvm-tyson/ddtrace/internal/generate"
+	"github.com/tuanvm-tyson/ddtrace/internal/scaffold"
 )
 
 func init() {
+	cli.RegisterCommand("cache", cache.NewCommand())
 	cli.RegisterCommand("gen", generate.NewGenerateCommand())
+	cli.RegisterCommand("init", scaffold.NewInitCommand())
+	cli.RegisterCommand("list", generate.NewListCommand())
 }
 
 func main() {
@@ -46,6 +51,13 @@ func main() {
 		if _, ok := err.(cli.CommandLineError); ok {
 			die(2, "%s\nRun 'ddtrace help %s' for usage.\n", err.Error(), args[0])
 		}
+		var exitErr cli.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err == nil {
+				os.Exit(exitErr.Code)
+			}
+			die(exitErr.Code, exitErr.Error())
+		}
 		die(1, err.Error())
 	}
 }
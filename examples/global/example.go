@@ -6,6 +6,8 @@ type Speak interface {
 	SayHello(ctx context.Context, name string) string
 }
 
+//ddtrace:service locomotion
 type Move interface {
+	//ddtrace:tag move.distance=distance
 	Walk(ctx context.Context, distance int) string
 }
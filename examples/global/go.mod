@@ -2,7 +2,7 @@ module github.com/tuanvm-tyson/ddtrace/examples/global
 
 go 1.24.4
 
-require gopkg.in/DataDog/dd-trace-go.v1 v1.74.2 // indirect
+require gopkg.in/DataDog/dd-trace-go.v1 v1.74.2
 
 replace github.com/tuanvm-tyson/ddtrace/tracing => ../../tracing
 
@@ -9,6 +9,7 @@ import (
 
 	_sourceGlobal "github.com/tuanvm-tyson/ddtrace/examples/global"
 	"github.com/tuanvm-tyson/ddtrace/tracing"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 // SpeakWithTracing implements Speak interface instrumented with Datadog tracing
@@ -17,6 +18,8 @@ type SpeakWithTracing struct {
 	_cfg tracing.TracingConfig
 }
 
+var _ _sourceGlobal.Speak = (*SpeakWithTracing)(nil)
+
 // NewSpeakWithTracing returns SpeakWithTracing
 func NewSpeakWithTracing(base _sourceGlobal.Speak, opts ...tracing.TracingOption) SpeakWithTracing {
 	return SpeakWithTracing{
@@ -25,6 +28,11 @@ func NewSpeakWithTracing(base _sourceGlobal.Speak, opts ...tracing.TracingOption
 	}
 }
 
+// Unwrap returns the Speak wrapped by SpeakWithTracing
+func (_d SpeakWithTracing) Unwrap() any {
+	return _d.Speak
+}
+
 // SayHello implements Speak
 func (_d SpeakWithTracing) SayHello(ctx context.Context, name string) (s1 string) {
 	span, ctx := _d._cfg.StartSpan(ctx, "Speak.SayHello")
@@ -37,11 +45,14 @@ func (_d SpeakWithTracing) SayHello(ctx context.Context, name string) (s1 string
 	return _d.Speak.SayHello(ctx, name)
 }
 
+// MoveWithTracing implements Move interface instrumented with Datadog tracing
 type MoveWithTracing struct {
 	_sourceGlobal.Move
 	_cfg tracing.TracingConfig
 }
 
+var _ _sourceGlobal.Move = (*MoveWithTracing)(nil)
+
 // NewMoveWithTracing returns MoveWithTracing
 func NewMoveWithTracing(base _sourceGlobal.Move, opts ...tracing.TracingOption) MoveWithTracing {
 	return MoveWithTracing{
@@ -50,9 +61,14 @@ func NewMoveWithTracing(base _sourceGlobal.Move, opts ...tracing.TracingOption)
 	}
 }
 
+// Unwrap returns the Move wrapped by MoveWithTracing
+func (_d MoveWithTracing) Unwrap() any {
+	return _d.Move
+}
+
 // Walk implements Move
 func (_d MoveWithTracing) Walk(ctx context.Context, distance int) (s1 string) {
-	span, ctx := _d._cfg.StartSpan(ctx, "Move.Walk")
+	span, ctx := _d._cfg.StartSpan(ctx, "Move.Walk", tracer.ServiceName("locomotion"), tracer.Tag("move.distance", distance))
 	defer func() {
 		_d._cfg.FinishSpan(span, nil, map[string]interface{}{
 			"ctx":      ctx,
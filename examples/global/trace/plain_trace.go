@@ -17,6 +17,8 @@ type FlyWithTracing struct {
 	_cfg tracing.TracingConfig
 }
 
+var _ _sourceGlobal.Fly = (*FlyWithTracing)(nil)
+
 // NewFlyWithTracing returns FlyWithTracing
 func NewFlyWithTracing(base _sourceGlobal.Fly, opts ...tracing.TracingOption) FlyWithTracing {
 	return FlyWithTracing{
@@ -25,6 +27,11 @@ func NewFlyWithTracing(base _sourceGlobal.Fly, opts ...tracing.TracingOption) Fl
 	}
 }
 
+// Unwrap returns the Fly wrapped by FlyWithTracing
+func (_d FlyWithTracing) Unwrap() any {
+	return _d.Fly
+}
+
 // SayHello implements Fly
 func (_d FlyWithTracing) SayHello(ctx context.Context) (s1 string) {
 	span, ctx := _d._cfg.StartSpan(ctx, "Fly.SayHello")
@@ -19,6 +19,8 @@ type SpeakWithTracing struct {
 	_cfg tracing.TracingConfig
 }
 
+var _ _sourceExamples.Speak = (*SpeakWithTracing)(nil)
+
 // NewSpeakWithTracing returns SpeakWithTracing
 func NewSpeakWithTracing(base _sourceExamples.Speak, opts ...tracing.TracingOption) SpeakWithTracing {
 	return SpeakWithTracing{
@@ -27,6 +29,11 @@ func NewSpeakWithTracing(base _sourceExamples.Speak, opts ...tracing.TracingOpti
 	}
 }
 
+// Unwrap returns the Speak wrapped by SpeakWithTracing
+func (_d SpeakWithTracing) Unwrap() any {
+	return _d.Speak
+}
+
 // SayHello implements Speak
 func (_d SpeakWithTracing) SayHello(ctx context.Context, name string) (s1 string) {
 	span, ctx := _d._cfg.StartSpan(ctx, "Speak.SayHello")
@@ -39,11 +46,14 @@ func (_d SpeakWithTracing) SayHello(ctx context.Context, name string) (s1 string
 	return _d.Speak.SayHello(ctx, name)
 }
 
+// MoveWithTracing implements Move interface instrumented with Datadog tracing
 type MoveWithTracing struct {
 	_sourceExamples.Move
 	_cfg tracing.TracingConfig
 }
 
+var _ _sourceExamples.Move = (*MoveWithTracing)(nil)
+
 // NewMoveWithTracing returns MoveWithTracing
 func NewMoveWithTracing(base _sourceExamples.Move, opts ...tracing.TracingOption) MoveWithTracing {
 	return MoveWithTracing{
@@ -52,6 +62,11 @@ func NewMoveWithTracing(base _sourceExamples.Move, opts ...tracing.TracingOption
 	}
 }
 
+// Unwrap returns the Move wrapped by MoveWithTracing
+func (_d MoveWithTracing) Unwrap() any {
+	return _d.Move
+}
+
 // Walk implements Move
 func (_d MoveWithTracing) Walk(ctx context.Context, distance int) (s1 string) {
 	span, ctx := _d._cfg.StartSpan(ctx, "Move.Walk")
@@ -6,6 +6,7 @@ toolchain go1.24.2
 
 require (
 	github.com/Masterminds/sprig/v3 v3.2.2
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/gojuno/minimock/v3 v3.0.10
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.10.0
@@ -32,4 +33,5 @@ require (
 	github.com/spf13/cast v1.4.1 // indirect
 	golang.org/x/crypto v0.37.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
 )
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+)
+
+// Command implements cli.Command interface
+type Command struct {
+	cli.BaseCommand
+}
+
+// NewCommand creates Command
+func NewCommand() *Command {
+	return &Command{
+		BaseCommand: cli.BaseCommand{
+			Short: "manage the persistent cache used by gen --cache",
+			Usage: "clean|stats",
+			Help: `
+clean removes every cached method set.
+stats prints the location, number of entries and size of the cache.
+
+The cache lives in $XDG_CACHE_HOME/ddtrace, or ddtrace in the user cache directory.
+`,
+			Flags: &flag.FlagSet{},
+		},
+	}
+}
+
+// Run implements cli.Command interface
+func (c *Command) Run(args []string, stdout io.Writer) error {
+	if err := c.FlagSet().Parse(args); err != nil {
+		return cli.CommandLineError(err.Error())
+	}
+	if c.FlagSet().NArg() != 1 {
+		return cli.CommandLineError("expected one of: clean, stats")
+	}
+
+	dir, err := DefaultDir()
+	if err != nil {
+		return errors.Wrap(err, "failed to locate cache directory")
+	}
+
+	switch action := c.FlagSet().Arg(0); action {
+	case "clean":
+		entries, size, err := usage(dir)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(filepath.Join(dir, methodsDir)); err != nil {
+			return errors.Wrap(err, "failed to clean cache")
+		}
+		_, err = fmt.Fprintf(stdout, "removed %d entries (%s) from %s\n", entries, formatSize(size), dir)
+		return err
+	case "stats":
+		entries, size, err := usage(dir)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "directory: %s\nentries:   %d\nsize:      %s\n", dir, entries, formatSize(size))
+		return err
+	default:
+		return cli.CommandLineError(fmt.Sprintf("unknown action %q, expected one of: clean, stats", action))
+	}
+}
+
+// usage returns the number of stored method sets and their total size in bytes.
+func usage(dir string) (entries int, size int64, err error) {
+	err = filepath.WalkDir(filepath.Join(dir, methodsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries++
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to read cache")
+	}
+	return entries, size, nil
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+)
+
+func TestCommand_Run(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", base)
+	dir := filepath.Join(base, "ddtrace")
+
+	hash := fakeHashes(map[string]string{"example.com/model": "h1:a"})
+	NewStore(dir, nil, hash).Store("example.com/model", "Reader", codegen.ResolvedInterface{})
+	NewStore(dir, nil, hash).Store("example.com/model", "Writer", codegen.ResolvedInterface{})
+
+	var out bytes.Buffer
+	require.NoError(t, NewCommand().Run([]string{"stats"}, &out))
+	assert.Contains(t, out.String(), "directory: "+dir+"\n")
+	assert.Contains(t, out.String(), "entries:   2\n")
+
+	out.Reset()
+	require.NoError(t, NewCommand().Run([]string{"clean"}, &out))
+	assert.Contains(t, out.String(), "removed 2 entries")
+
+	_, ok := NewStore(dir, nil, hash).Load("example.com/model", "Reader")
+	assert.False(t, ok)
+
+	out.Reset()
+	require.NoError(t, NewCommand().Run([]string{"stats"}, &out))
+	assert.Contains(t, out.String(), "entries:   0\n")
+}
+
+func TestCommand_Run_Usage(t *testing.T) {
+	for _, args := range [][]string{nil, {"purge"}, {"clean", "stats"}} {
+		err := NewCommand().Run(args, &bytes.Buffer{})
+		assert.IsType(t, cli.CommandLineError(""), err, args)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.5 KiB", formatSize(1536))
+	assert.Equal(t, "2.0 MiB", formatSize(2<<20))
+}
@@ -0,0 +1,203 @@
+// Package cache implements the persistent cache of interface method sets
+// shared by ddtrace runs.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
+)
+
+// formatVersion is bumped whenever the layout of stored method sets changes.
+const formatVersion = "2"
+
+// methodsDir is the subdirectory of the cache directory holding method sets.
+const methodsDir = "methods"
+
+// HashFunc returns the hash of the sources a package is built from: a content hash
+// or a module version. ok is false when the package can't be hashed.
+type HashFunc func(importPath string) (hash string, ok bool, err error)
+
+// Store is a codegen.MethodStore keeping one JSON file per interface in a directory.
+// A stored method set is only used while every package it was resolved from still
+// has the recorded hash. Hashes and method sets are read once per Store, so a Store
+// must not outlive the sources it's used for.
+type Store struct {
+	dir  string
+	tags []string
+	hash HashFunc
+
+	mu      sync.Mutex
+	hashes  map[string]string
+	entries map[string]codegen.ResolvedInterface
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+var _ codegen.MethodStore = (*Store)(nil)
+
+// DefaultDir returns $XDG_CACHE_HOME/ddtrace, or ddtrace in the user cache directory
+// when XDG_CACHE_HOME is not set.
+func DefaultDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		if base, err = os.UserCacheDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(base, "ddtrace"), nil
+}
+
+// NewStore returns a Store keeping method sets in dir and validating them with hash.
+// Method sets are stored for the build tags they are resolved with.
+func NewStore(dir string, tags []string, hash HashFunc) *Store {
+	return &Store{
+		dir:     dir,
+		tags:    slices.Sorted(slices.Values(tags)),
+		hash:    hash,
+		hashes:  map[string]string{},
+		entries: map[string]codegen.ResolvedInterface{},
+	}
+}
+
+type entry struct {
+	Package   string            `json:"package"`
+	Interface string            `json:"interface"`
+	Hashes    map[string]string `json:"hashes"`
+
+	codegen.ResolvedInterface
+}
+
+// Load implements codegen.MethodStore
+func (s *Store) Load(importPath, name string) (codegen.ResolvedInterface, bool) {
+	path := s.path(importPath, name)
+	if ri, ok := s.entry(path); ok {
+		return ri, true
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.misses.Add(1)
+		return codegen.ResolvedInterface{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Package != importPath || e.Interface != name || !s.valid(e.Hashes) {
+		s.misses.Add(1)
+		return codegen.ResolvedInterface{}, false
+	}
+
+	s.hits.Add(1)
+	s.remember(path, e.ResolvedInterface)
+	return e.ResolvedInterface, true
+}
+
+// Store implements codegen.MethodStore. Method sets resolved from packages that
+// can't be hashed aren't stored; write errors are ignored as the cache is optional.
+func (s *Store) Store(importPath, name string, ri codegen.ResolvedInterface) {
+	hashes := make(map[string]string, len(ri.Deps)+1)
+	for _, path := range append([]string{importPath}, ri.Deps...) {
+		hash, ok := s.packageHash(path)
+		if !ok {
+			return
+		}
+		hashes[path] = hash
+	}
+
+	data, err := json.Marshal(entry{Package: importPath, Interface: name, Hashes: hashes, ResolvedInterface: ri})
+	if err != nil {
+		return
+	}
+
+	path := s.path(importPath, name)
+	s.remember(path, ri)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	// Write to a temporary file first so concurrent runs never read a partial entry.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name()) //nolint: errcheck
+	}
+}
+
+// Stats returns the number of method sets found and not found in the store since it was created.
+// Method sets already loaded or stored by s are not counted again.
+func (s *Store) Stats() (hits, misses int64) {
+	return s.hits.Load(), s.misses.Load()
+}
+
+func (s *Store) entry(path string) (codegen.ResolvedInterface, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ri, ok := s.entries[path]
+	return ri, ok
+}
+
+func (s *Store) remember(path string, ri codegen.ResolvedInterface) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[path] = ri
+}
+
+func (s *Store) valid(hashes map[string]string) bool {
+	if len(hashes) == 0 {
+		return false
+	}
+	for path, recorded := range hashes {
+		if hash, ok := s.packageHash(path); !ok || hash != recorded {
+			return false
+		}
+	}
+	return true
+}
+
+// packageHash returns the hash of a package, computed once per Store.
+func (s *Store) packageHash(importPath string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if hash, ok := s.hashes[importPath]; ok {
+		return hash, hash != ""
+	}
+
+	hash, ok, err := s.hash(importPath)
+	if err != nil || !ok {
+		hash = ""
+	}
+	s.hashes[importPath] = hash
+	return hash, hash != ""
+}
+
+// path returns the file of the method set of the interface name declared in importPath.
+// Method sets depend on the files matching the build tags and platform, so both are
+// part of the key.
+func (s *Store) path(importPath, name string) string {
+	key := []string{formatVersion, cli.Version(), strings.Join(s.tags, ","), scanner.Platform(), importPath, name}
+	sum := sha256.Sum256([]byte(strings.Join(key, "\x00")))
+	hash := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, methodsDir, hash[:2], hash+".json")
+}
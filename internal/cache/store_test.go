@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+)
+
+// fakeHashes returns a HashFunc reading hashes, reporting unknown packages as unhashable.
+func fakeHashes(hashes map[string]string) HashFunc {
+	return func(importPath string) (string, bool, error) {
+		hash, ok := hashes[importPath]
+		return hash, ok, nil
+	}
+}
+
+func TestStore(t *testing.T) {
+	resolved := codegen.ResolvedInterface{
+		Methods: map[string]codegen.Method{
+			"Read": {Name: "Read", AcceptsContext: true, ReturnsError: true, Params: codegen.ParamsSlice{{Name: "ctx", Type: "context.Context"}}},
+		},
+		Deps: []string{"example.com/base"},
+	}
+
+	tests := []struct {
+		name   string
+		stored map[string]string
+		loaded map[string]string
+		wantOK bool
+	}{
+		{
+			name:   "unchanged",
+			stored: map[string]string{"example.com/model": "h1:a", "example.com/base": "h1:b"},
+			loaded: map[string]string{"example.com/model": "h1:a", "example.com/base": "h1:b"},
+			wantOK: true,
+		},
+		{
+			name:   "package changed",
+			stored: map[string]string{"example.com/model": "h1:a", "example.com/base": "h1:b"},
+			loaded: map[string]string{"example.com/model": "h1:c", "example.com/base": "h1:b"},
+		},
+		{
+			name:   "dependency changed",
+			stored: map[string]string{"example.com/model": "h1:a", "example.com/base": "h1:b"},
+			loaded: map[string]string{"example.com/model": "h1:a", "example.com/base": "h1:c"},
+		},
+		{
+			name:   "dependency not hashable",
+			stored: map[string]string{"example.com/model": "h1:a"},
+			loaded: map[string]string{"example.com/model": "h1:a", "example.com/base": "h1:b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			NewStore(dir, nil, fakeHashes(tt.stored)).Store("example.com/model", "Reader", resolved)
+
+			store := NewStore(dir, nil, fakeHashes(tt.loaded))
+			got, ok := store.Load("example.com/model", "Reader")
+			require.Equal(t, tt.wantOK, ok)
+
+			hits, misses := store.Stats()
+			if tt.wantOK {
+				assert.Equal(t, resolved, got)
+				assert.Equal(t, [2]int64{1, 0}, [2]int64{hits, misses})
+			} else {
+				assert.Equal(t, [2]int64{0, 1}, [2]int64{hits, misses})
+			}
+		})
+	}
+}
+
+func TestStore_LoadOtherInterface(t *testing.T) {
+	dir := t.TempDir()
+	hash := fakeHashes(map[string]string{"example.com/model": "h1:a"})
+	NewStore(dir, nil, hash).Store("example.com/model", "Reader", codegen.ResolvedInterface{})
+
+	_, ok := NewStore(dir, nil, hash).Load("example.com/model", "Writer")
+	assert.False(t, ok)
+}
+
+func TestStore_LoadOtherBuildTags(t *testing.T) {
+	dir := t.TempDir()
+	hash := fakeHashes(map[string]string{"example.com/model": "h1:a"})
+	NewStore(dir, []string{"b", "a"}, hash).Store("example.com/model", "Reader", codegen.ResolvedInterface{})
+
+	_, ok := NewStore(dir, []string{"b"}, hash).Load("example.com/model", "Reader")
+	assert.False(t, ok)
+
+	_, ok = NewStore(dir, []string{"a", "b"}, hash).Load("example.com/model", "Reader")
+	assert.True(t, ok)
+}
+
+func TestStore_LoadTwice(t *testing.T) {
+	dir := t.TempDir()
+	hash := fakeHashes(map[string]string{"example.com/model": "h1:a"})
+	NewStore(dir, nil, hash).Store("example.com/model", "Reader", codegen.ResolvedInterface{})
+
+	store := NewStore(dir, nil, hash)
+	for range 2 {
+		_, ok := store.Load("example.com/model", "Reader")
+		require.True(t, ok)
+	}
+
+	hits, misses := store.Stats()
+	assert.Equal(t, [2]int64{1, 0}, [2]int64{hits, misses})
+}
@@ -6,3 +6,22 @@ type CommandLineError string
 func (e CommandLineError) Error() string {
 	return string(e)
 }
+
+// ExitError is returned from the commands to exit with a specific code.
+// If Err is nil the process exits without printing a message.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ExitError) Unwrap() error {
+	return e.Err
+}
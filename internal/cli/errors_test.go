@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,3 +10,10 @@ import (
 func TestCommandLineError_Error(t *testing.T) {
 	assert.Equal(t, "error", CommandLineError("error").Error())
 }
+
+func TestExitError_Error(t *testing.T) {
+	err := errors.New("failed")
+	assert.Equal(t, "failed", ExitError{Code: 1, Err: err}.Error())
+	assert.Equal(t, "", ExitError{Code: 3}.Error())
+	assert.ErrorIs(t, ExitError{Code: 1, Err: err}, err)
+}
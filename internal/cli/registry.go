@@ -8,6 +8,11 @@ import (
 
 var version string = "dev"
 
+// Version returns the ddtrace version, set at build time
+func Version() string {
+	return version
+}
+
 var commands = map[string]Command{}
 
 // RegisterCommand adds command to the global Commands map
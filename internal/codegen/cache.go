@@ -13,29 +13,86 @@ import (
 // work when multiple interfaces reference the same external packages.
 // All methods are safe for concurrent use by multiple goroutines.
 type PackageCache struct {
+	tags   []string
 	mu     sync.RWMutex
 	loaded map[string]*packages.Package
 	asts   map[string]*scanner.Package
+	store  MethodStore
 }
 
-// NewPackageCache returns an empty PackageCache.
-func NewPackageCache() *PackageCache {
+// MethodStore persists the method sets of interfaces declared in other packages
+// across runs, so resolving them doesn't require loading their packages.
+// Implementations must be safe for concurrent use.
+type MethodStore interface {
+	// Load returns the stored method set of the interface name declared in importPath,
+	// if it is still valid.
+	Load(importPath, name string) (ResolvedInterface, bool)
+
+	// Store records the method set of the interface name declared in importPath.
+	Store(importPath, name string, ri ResolvedInterface)
+}
+
+// ResolvedInterface is the method set of an interface together with the other
+// packages it was resolved from, including those of transitively embedded interfaces.
+type ResolvedInterface struct {
+	Methods map[string]Method `json:"methods"`
+	Deps    []string          `json:"deps,omitempty"`
+}
+
+// NewPackageCache returns an empty PackageCache loading packages with the build tags.
+func NewPackageCache(tags []string) *PackageCache {
 	return &PackageCache{
+		tags:   tags,
 		loaded: make(map[string]*packages.Package),
 		asts:   make(map[string]*scanner.Package),
 	}
 }
 
+// Tags returns the build tags packages are loaded with. A nil cache has none.
+func (c *PackageCache) Tags() []string {
+	if c == nil {
+		return nil
+	}
+	return c.tags
+}
+
 // Seed pre-populates the cache with already-loaded packages (e.g. from batch loading).
+// The parsed AST of a replaced package is dropped.
 func (c *PackageCache) Seed(pkgs map[string]*packages.Package) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for path, p := range pkgs {
+		if c.loaded[path] != p {
+			delete(c.asts, path)
+		}
 		c.loaded[path] = p
 	}
 }
 
+// UseStore makes the cache look up and record the method sets of interfaces
+// declared in other packages in store.
+func (c *PackageCache) UseStore(store MethodStore) {
+	c.store = store
+}
+
+// Invalidate drops the cached packages and ASTs of the given import paths,
+// e.g. after their source files changed.
+func (c *PackageCache) Invalidate(paths ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, path := range paths {
+		delete(c.loaded, path)
+		delete(c.asts, path)
+	}
+}
+
+// load returns the package of an import path, loading it once. A nil cache loads
+// the package on every call.
 func (c *PackageCache) load(path string) (*packages.Package, error) {
+	if c == nil {
+		return scanner.Load(path, nil)
+	}
+
 	c.mu.RLock()
 	if p, ok := c.loaded[path]; ok {
 		c.mu.RUnlock()
@@ -43,7 +100,7 @@ func (c *PackageCache) load(path string) (*packages.Package, error) {
 	}
 	c.mu.RUnlock()
 
-	p, err := scanner.Load(path)
+	p, err := scanner.Load(path, c.tags)
 	if err != nil {
 		return nil, err
 	}
@@ -72,3 +129,39 @@ func (c *PackageCache) ast(fs *token.FileSet, p *packages.Package) (*scanner.Pac
 	c.mu.Unlock()
 	return a, nil
 }
+
+// resolve returns the method set of the interface name declared in importPath,
+// from the method store when possible, and records the packages it was resolved
+// from in deps. Interfaces instantiated with type arguments are never stored.
+func (c *PackageCache) resolve(importPath, name string, params genericParams, deps dependencies, resolve func(dependencies) (methodsList, error)) (methodsList, error) {
+	deps.add(importPath)
+	if c == nil || c.store == nil || len(params) > 0 {
+		return resolve(deps)
+	}
+
+	if ri, ok := c.store.Load(importPath, name); ok {
+		for _, path := range ri.Deps {
+			deps.add(path)
+		}
+		return ri.Methods, nil
+	}
+
+	resolved := dependencies{}
+	methods, err := resolve(resolved)
+	if err != nil {
+		// The packages that failed to resolve the method set are dependencies too:
+		// fixing them changes the outcome.
+		for path := range resolved {
+			deps.add(path)
+		}
+		return nil, err
+	}
+
+	ri := ResolvedInterface{Methods: methods, Deps: resolved.sorted(importPath)}
+	for _, path := range ri.Deps {
+		deps.add(path)
+	}
+	c.store.Store(importPath, name, ri)
+
+	return methods, nil
+}
@@ -33,6 +33,7 @@ type Generator struct {
 	genericTypes   string
 	genericParams  string
 	localPrefix    string
+	dependencies   []string
 }
 
 // TemplateInputs information passed to template for generation
@@ -109,6 +110,10 @@ type Options struct {
 	BodyTemplateParsed         *template.Template
 	FileSet                    *token.FileSet
 	PackageCache               *PackageCache
+
+	// SourcePackageTyped is the type-checked source package. When set, the interface's
+	// method set is resolved with go/types instead of the source package's AST.
+	SourcePackageTyped *packages.Package
 }
 
 type methodsList map[string]Method
@@ -120,6 +125,17 @@ type processInput struct {
 	targetName     string
 	genericParams  genericParams
 	pkgCache       *PackageCache
+	deps           dependencies
+}
+
+// dependencies collects the import paths of the packages loaded to resolve embedded interfaces.
+// A nil dependencies records nothing.
+type dependencies map[string]struct{}
+
+func (d dependencies) add(path string) {
+	if d != nil {
+		d[path] = struct{}{}
+	}
 }
 
 type targetProcessInput struct {
@@ -183,7 +199,7 @@ func NewGenerator(options Options) (*Generator, error) {
 		srcPackage = options.SourcePackageInstance
 	} else {
 		var err error
-		srcPackage, err = scanner.Load(options.SourcePackage)
+		srcPackage, err = scanner.Load(options.SourcePackage, options.PackageCache.Tags())
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to load source package")
 		}
@@ -199,7 +215,7 @@ func NewGenerator(options Options) (*Generator, error) {
 		dstPackage = options.DestinationPackageInstance
 	} else {
 		var err error
-		dstPackage, err = loadDestinationPackage(dstPackagePath)
+		dstPackage, err = loadDestinationPackage(dstPackagePath, options.PackageCache.Tags())
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to load destination package: %s", dstPackagePath)
 		}
@@ -234,13 +250,21 @@ func NewGenerator(options Options) (*Generator, error) {
 		options.Imports = append(options.Imports, srcPackageAST.Name+` "`+srcPackage.PkgPath+`"`)
 	}
 
-	output, err := findTarget(processInput{
-		fileSet:        fs,
-		currentPackage: srcPackage,
-		astPackage:     srcPackageAST,
-		targetName:     options.InterfaceName,
-		pkgCache:       options.PackageCache,
-	})
+	deps := dependencies{}
+	var output processOutput
+	var err error
+	if options.SourcePackageTyped != nil {
+		output, err = findTypedTarget(options.SourcePackageTyped, options.InterfaceName, srcPackageAST.Name, deps)
+	} else {
+		output, err = findTarget(processInput{
+			fileSet:        fs,
+			currentPackage: srcPackage,
+			astPackage:     srcPackageAST,
+			targetName:     options.InterfaceName,
+			pkgCache:       options.PackageCache,
+			deps:           deps,
+		})
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse interface declaration")
 	}
@@ -270,9 +294,28 @@ func NewGenerator(options Options) (*Generator, error) {
 		genericParams:  genericParams,
 		methods:        output.methods,
 		localPrefix:    options.LocalPrefix,
+		dependencies:   deps.sorted(srcPackage.PkgPath),
 	}, nil
 }
 
+// sorted returns the collected import paths except self, sorted.
+func (d dependencies) sorted(self string) []string {
+	paths := make([]string, 0, len(d))
+	for path := range d {
+		if path != self {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Dependencies returns the import paths of the other packages the interface's method set
+// was resolved from, including the packages of transitively embedded interfaces.
+func (g Generator) Dependencies() []string {
+	return g.dependencies
+}
+
 func makeImports(imports []*ast.ImportSpec) []string {
 	result := make([]string, len(imports))
 	for i, im := range imports {
@@ -286,8 +329,8 @@ func makeImports(imports []*ast.ImportSpec) []string {
 	return result
 }
 
-func loadDestinationPackage(path string) (*packages.Package, error) {
-	dstPackage, err := scanner.Load(path)
+func loadDestinationPackage(path string, tags []string) (*packages.Package, error) {
+	dstPackage, err := scanner.Load(path, tags)
 	if err != nil {
 		dstPackage, err = makePackage(path)
 	}
@@ -356,6 +399,36 @@ func (g Generator) Generate(w io.Writer) error {
 
 var errTargetNotFound = errors.New("target declaration not found")
 
+// InterfaceMethods returns the method set of the named interface declared in srcPackage,
+// including the methods of embedded interfaces, keyed by method name. It's resolved with
+// go/types when typedPackage is set, from the AST otherwise. It also returns the other
+// packages the method set was resolved from, or that failed to resolve it on error.
+func InterfaceMethods(fs *token.FileSet, srcPackage *packages.Package, srcPackageAST *scanner.Package, typedPackage *packages.Package, name string, cache *PackageCache) (map[string]Method, []string, error) {
+	deps := dependencies{}
+	var output processOutput
+	var err error
+	if typedPackage != nil {
+		output, err = findTypedTarget(typedPackage, name, srcPackageAST.Name, deps)
+	} else {
+		output, err = findTarget(processInput{
+			fileSet:        fs,
+			currentPackage: srcPackage,
+			astPackage:     &scanner.Package{Name: srcPackageAST.Name, Files: srcPackageAST.Files},
+			targetName:     name,
+			pkgCache:       cache,
+			deps:           deps,
+		})
+	}
+	if err != nil {
+		return nil, deps.sorted(srcPackage.PkgPath), errors.Wrap(err, "failed to parse interface declaration")
+	}
+	if len(output.methods) == 0 {
+		return nil, deps.sorted(srcPackage.PkgPath), errEmptyInterface
+	}
+
+	return output.methods, deps.sorted(srcPackage.PkgPath), nil
+}
+
 func findTarget(input processInput) (output processOutput, err error) {
 	ts, imps, types := iterateFiles(input.astPackage, input.targetName)
 	if ts == nil {
@@ -405,44 +478,42 @@ func findMethods(selectedType *ast.TypeSpec, input targetProcessInput) (methods
 	return
 }
 
-func getMethods(sel *ast.SelectorExpr, srcPackagePath string, ctx processInput) (methods methodsList, err error) {
-	var srcPkg *packages.Package
-	if ctx.pkgCache != nil {
-		srcPkg, err = ctx.pkgCache.load(srcPackagePath)
-	} else {
-		srcPkg, err = scanner.Load(srcPackagePath)
-	}
-	if err != nil {
-		return nil, errors.Wrapf(err, "cant load %s package", srcPackagePath)
-	}
+func getMethods(sel *ast.SelectorExpr, srcPackagePath string, ctx processInput) (methodsList, error) {
+	return ctx.pkgCache.resolve(srcPackagePath, sel.Sel.Name, nil, ctx.deps, func(deps dependencies) (methodsList, error) {
+		srcPkg, err := ctx.pkgCache.load(srcPackagePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cant load %s package", srcPackagePath)
+		}
 
-	fs := ctx.fileSet
-	if fs == nil {
-		fs = token.NewFileSet()
-	}
+		fs := ctx.fileSet
+		if fs == nil {
+			fs = token.NewFileSet()
+		}
 
-	var srcAst *scanner.Package
-	if ctx.pkgCache != nil {
-		srcAst, err = ctx.pkgCache.ast(fs, srcPkg)
-	} else {
-		srcAst, err = scanner.AST(fs, srcPkg)
-	}
-	if err != nil {
-		return nil, errors.Wrapf(err, "cant ast %s package", srcPackagePath)
-	}
+		var srcAst *scanner.Package
+		if ctx.pkgCache != nil {
+			srcAst, err = ctx.pkgCache.ast(fs, srcPkg)
+		} else {
+			srcAst, err = scanner.AST(fs, srcPkg)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cant ast %s package", srcPackagePath)
+		}
 
-	out, err := findTarget(processInput{
-		fileSet:        fs,
-		currentPackage: srcPkg,
-		astPackage:     srcAst,
-		targetName:     sel.Sel.Name,
-		pkgCache:       ctx.pkgCache,
-	})
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to find target in %s package", srcPackagePath)
-	}
+		out, err := findTarget(processInput{
+			fileSet:        fs,
+			currentPackage: srcPkg,
+			astPackage:     srcAst,
+			targetName:     sel.Sel.Name,
+			pkgCache:       ctx.pkgCache,
+			deps:           deps,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find target in %s package", srcPackagePath)
+		}
 
-	return out.methods, nil
+		return out.methods, nil
+	})
 }
 
 func findSourcePackage(ident *ast.Ident, imports []*ast.ImportSpec) string {
@@ -613,43 +684,42 @@ func processSelector(se *ast.SelectorExpr, input targetProcessInput) (methodsLis
 		return nil, errors.Wrapf(err, "unable to find package %s", packageSelector)
 	}
 
-	// Try Imports map first (available when loaded with NeedDeps).
-	// Fall back to lazy loading via cache for filesystem-built packages.
-	var p *packages.Package
-	if input.currentPackage.Imports != nil {
-		p = input.currentPackage.Imports[importPath]
-	}
-	if p == nil {
-		if input.pkgCache != nil {
+	return input.pkgCache.resolve(importPath, selectedName, input.genericParams, input.deps, func(deps dependencies) (methodsList, error) {
+		// Try Imports map first (available when loaded with NeedDeps).
+		// Fall back to lazy loading via cache for filesystem-built packages.
+		var p *packages.Package
+		if input.currentPackage.Imports != nil {
+			p = input.currentPackage.Imports[importPath]
+		}
+		if p == nil {
 			p, err = input.pkgCache.load(importPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to load package %s", packageSelector)
+			}
+		}
+
+		var astPkg *scanner.Package
+		if input.pkgCache != nil {
+			astPkg, err = input.pkgCache.ast(input.fileSet, p)
 		} else {
-			p, err = scanner.Load(importPath)
+			astPkg, err = scanner.AST(input.fileSet, p)
 		}
 		if err != nil {
-			return nil, errors.Wrapf(err, "unable to load package %s", packageSelector)
+			return nil, errors.Wrap(err, "failed to import package")
 		}
-	}
 
-	var astPkg *scanner.Package
-	if input.pkgCache != nil {
-		astPkg, err = input.pkgCache.ast(input.fileSet, p)
-	} else {
-		astPkg, err = scanner.AST(input.fileSet, p)
-	}
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to import package")
-	}
+		output, err := findTarget(processInput{
+			fileSet:        input.fileSet,
+			currentPackage: p,
+			astPackage:     astPkg,
+			targetName:     selectedName,
+			genericParams:  input.genericParams,
+			pkgCache:       input.pkgCache,
+			deps:           deps,
+		})
 
-	output, err := findTarget(processInput{
-		fileSet:        input.fileSet,
-		currentPackage: p,
-		astPackage:     astPkg,
-		targetName:     selectedName,
-		genericParams:  input.genericParams,
-		pkgCache:       input.pkgCache,
+		return output.methods, err
 	})
-
-	return output.methods, err
 }
 
 func mergeMethods(methods, embeddedMethods methodsList) (methodsList, error) {
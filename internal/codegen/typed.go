@@ -0,0 +1,315 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// typedImports qualifies the types printed from go/types and records the imports they need.
+// Types declared in the source package are qualified with its alias, other packages with
+// their declared name, renamed when it collides with another package.
+type typedImports struct {
+	self  *types.Package
+	alias string
+	names map[string]string // import path → name
+	paths map[string]string // name → import path
+}
+
+func newTypedImports(self *types.Package, alias string) *typedImports {
+	ti := &typedImports{
+		self:  self,
+		alias: alias,
+		names: map[string]string{},
+		paths: map[string]string{},
+	}
+	if alias != "" {
+		ti.paths[alias] = self.Path()
+	}
+	return ti
+}
+
+func (ti *typedImports) qualifier(p *types.Package) string {
+	if p == ti.self {
+		return ti.alias
+	}
+	if name, ok := ti.names[p.Path()]; ok {
+		return name
+	}
+
+	name := p.Name()
+	for i := 2; ti.paths[name] != ""; i++ {
+		name = p.Name() + strconv.Itoa(i)
+	}
+	ti.names[p.Path()] = name
+	ti.paths[name] = p.Path()
+	return name
+}
+
+// specs returns the import specs of the qualified packages sorted by import path,
+// naming those whose name differs from the last element of their path.
+func (ti *typedImports) specs() []*ast.ImportSpec {
+	paths := make([]string, 0, len(ti.names))
+	for path := range ti.names {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	specs := make([]*ast.ImportSpec, 0, len(paths))
+	for _, importPath := range paths {
+		spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath)}}
+		if name := ti.names[importPath]; name != path.Base(importPath) {
+			spec.Name = ast.NewIdent(name)
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// typedFields finds the AST fields methods are declared with, so their comments and
+// directives are kept. Files of the loaded packages are reused, other files are parsed on demand.
+type typedFields struct {
+	fset   *token.FileSet
+	loaded map[string]*ast.File
+	parsed map[string]*ast.File
+	pfset  *token.FileSet
+}
+
+func newTypedFields(pkg *packages.Package) *typedFields {
+	tf := &typedFields{
+		fset:   pkg.Fset,
+		loaded: map[string]*ast.File{},
+		parsed: map[string]*ast.File{},
+		pfset:  token.NewFileSet(),
+	}
+	for _, f := range pkg.Syntax {
+		tf.loaded[pkg.Fset.Position(f.Pos()).Filename] = f
+	}
+	return tf
+}
+
+// field returns the interface field fn is declared with, or nil when its source isn't available.
+func (tf *typedFields) field(fn *types.Func) *ast.Field {
+	pos := tf.fset.Position(fn.Pos())
+	if !pos.IsValid() {
+		return nil
+	}
+
+	fset, file := tf.fset, tf.loaded[pos.Filename]
+	if file == nil {
+		var ok bool
+		if file, ok = tf.parsed[pos.Filename]; !ok {
+			file, _ = parser.ParseFile(tf.pfset, pos.Filename, nil, parser.ParseComments)
+			tf.parsed[pos.Filename] = file
+		}
+		if file == nil {
+			return nil
+		}
+		fset = tf.pfset
+	}
+
+	var found *ast.Field
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		it, ok := n.(*ast.InterfaceType)
+		if !ok {
+			return true
+		}
+		for _, f := range it.Methods.List {
+			for _, name := range f.Names {
+				if name.Name == fn.Name() && fset.Position(name.Pos()).Line == pos.Line {
+					found = f
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// findTypedTarget builds the method set of the interface name declared in the type-checked
+// package pkg. Types declared in pkg are qualified with alias. It returns the import paths
+// of the packages the method set was resolved from in deps.
+func findTypedTarget(pkg *packages.Package, name, alias string, deps dependencies) (output processOutput, err error) {
+	if pkg.Types == nil {
+		return output, errors.Errorf("package %s is not type-checked", pkg.PkgPath)
+	}
+
+	obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return output, errors.Errorf("type %s was not found", name)
+	}
+
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return output, errors.Errorf("%s is not an interface", name)
+	}
+
+	ti := newTypedImports(pkg.Types, alias)
+
+	if named, ok := types.Unalias(obj.Type()).(*types.Named); ok && named.TypeParams() != nil {
+		for i := 0; i < named.TypeParams().Len(); i++ {
+			tp := named.TypeParams().At(i)
+			output.genericTypes = append(output.genericTypes, genericType{
+				Names: []string{tp.Obj().Name()},
+				Type:  types.TypeString(tp.Constraint(), ti.qualifier),
+			})
+		}
+	}
+
+	deps.add(pkg.PkgPath)
+	embeddedDeps(iface, deps, map[*types.Interface]bool{})
+
+	tf := newTypedFields(pkg)
+	output.methods = make(methodsList, iface.NumMethods())
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		m, err := newTypedMethod(fn, tf.field(fn), ti.qualifier)
+		if err != nil {
+			return output, err
+		}
+		output.methods[fn.Name()] = *m
+	}
+
+	output.imports = ti.specs()
+	return output, nil
+}
+
+// embeddedDeps adds the packages of the named interfaces embedded in iface, transitively.
+func embeddedDeps(iface *types.Interface, deps dependencies, seen map[*types.Interface]bool) {
+	if seen[iface] {
+		return
+	}
+	seen[iface] = true
+
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		t := types.Unalias(iface.EmbeddedType(i))
+		if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+			deps.add(named.Obj().Pkg().Path())
+		}
+		if embedded, ok := t.Underlying().(*types.Interface); ok {
+			embeddedDeps(embedded, deps, seen)
+		}
+	}
+}
+
+// newTypedMethod returns the Method of fn with types qualified by qf.
+// fi is the field the method is declared with, if its source is available.
+func newTypedMethod(fn *types.Func, fi *ast.Field, qf types.Qualifier) (*Method, error) {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a method", fn.Name())
+	}
+
+	m := Method{Name: fn.Name()}
+	if fi != nil {
+		if err := m.setComments(fi); err != nil {
+			return nil, err
+		}
+	}
+
+	usedNames := map[string]bool{}
+
+	if sig.Results().Len() > 0 {
+		last := sig.Results().At(sig.Results().Len() - 1).Type()
+		m.ReturnsError = types.Identical(last, types.Universe.Lookup("error").Type())
+		usedNames["err"] = true
+	}
+
+	if sig.Params().Len() > 0 {
+		m.AcceptsContext = isContext(sig.Params().At(0).Type())
+		if m.AcceptsContext {
+			usedNames["ctx"] = true
+		}
+	}
+
+	m.Params = makeTypedParams(sig.Params(), sig.Variadic(), usedNames, qf)
+	m.Results = makeTypedParams(sig.Results(), false, usedNames, qf)
+
+	if m.ReturnsError {
+		m.Results[len(m.Results)-1].Name = "err"
+	}
+
+	if m.AcceptsContext {
+		m.Params[0].Name = "ctx"
+	}
+
+	if err := m.checkTags(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func isContext(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func makeTypedParams(tuple *types.Tuple, variadic bool, usedNames map[string]bool, qf types.Qualifier) ParamsSlice {
+	if tuple.Len() == 0 {
+		return nil
+	}
+
+	result := make(ParamsSlice, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+		p := Param{Name: v.Name(), Variadic: variadic && i == tuple.Len()-1}
+
+		t := v.Type()
+		if p.Variadic {
+			t = t.(*types.Slice).Elem()
+			p.Type = "..." + types.TypeString(t, qf)
+		} else {
+			p.Type = types.TypeString(t, qf)
+		}
+
+		if p.Name == "" || p.Name == "_" || usedNames[p.Name] {
+			p.Name = genName(typedPrefix(t), 1, usedNames)
+		}
+		usedNames[p.Name] = true
+
+		result = append(result, p)
+	}
+	return result
+}
+
+// typedPrefix is the go/types counterpart of typePrefix.
+func typedPrefix(t types.Type) string {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		return strings.ToLower(t.Obj().Name()[0:1])
+	case *types.TypeParam:
+		return strings.ToLower(t.Obj().Name()[0:1])
+	case *types.Basic:
+		return strings.ToLower(t.Name()[0:1])
+	case *types.Pointer:
+		return typedPrefix(t.Elem()) + "p"
+	case *types.Slice:
+		return typedPrefix(t.Elem()) + "a"
+	case *types.Array:
+		return typedPrefix(t.Elem()) + "a"
+	case *types.Map:
+		return "m"
+	case *types.Chan:
+		return "ch"
+	case *types.Struct:
+		return "st"
+	case *types.Signature:
+		return "f"
+	}
+
+	return "p"
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"go/ast"
 	"strings"
+	"time"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
 )
 
 type typePrinter interface {
@@ -20,6 +23,16 @@ type Method struct {
 
 	ReturnsError   bool
 	AcceptsContext bool
+
+	// Ignored reports whether the method is annotated with //ddtrace:ignore: decorators
+	// pass it through to the base implementation.
+	Ignored bool
+
+	// Timeout is the deadline declared with a //ddtrace:timeout directive in the method's comments.
+	Timeout time.Duration
+
+	// Span holds the span directives of the method's comments.
+	Span scanner.SpanDirectives
 }
 
 // Param represents fuction argument or result
@@ -73,18 +86,8 @@ func NewMethod(name string, fi *ast.Field, printer typePrinter, genericTypes gen
 	}
 
 	m := Method{Name: name}
-	if fi.Doc != nil && len(fi.Doc.List) > 0 {
-		m.Doc = make([]string, 0, len(fi.Doc.List))
-		for _, comment := range fi.Doc.List {
-			m.Doc = append(m.Doc, comment.Text)
-		}
-	}
-
-	if fi.Comment != nil && len(fi.Comment.List) > 0 {
-		m.Comment = make([]string, 0, len(fi.Comment.List))
-		for _, comment := range fi.Comment.List {
-			m.Comment = append(m.Comment, comment.Text)
-		}
+	if err := m.setComments(fi); err != nil {
+		return nil, err
 	}
 
 	usedNames := map[string]bool{}
@@ -121,9 +124,116 @@ func NewMethod(name string, fi *ast.Field, printer typePrinter, genericTypes gen
 		m.Params[0].Name = "ctx"
 	}
 
+	if err := m.checkTags(); err != nil {
+		return nil, err
+	}
+
 	return &m, nil
 }
 
+// DirectiveError is an invalid //ddtrace: directive of a method.
+type DirectiveError struct {
+	Method string
+	Err    error
+}
+
+func (e *DirectiveError) Error() string {
+	return fmt.Sprintf("%q: %v", e.Method, e.Err)
+}
+
+func (e *DirectiveError) Unwrap() error {
+	return e.Err
+}
+
+// setComments copies the doc and line comments of the method's field and applies its directives.
+func (m *Method) setComments(fi *ast.Field) error {
+	if fi.Doc != nil && len(fi.Doc.List) > 0 {
+		m.Doc = make([]string, 0, len(fi.Doc.List))
+		for _, comment := range fi.Doc.List {
+			m.Doc = append(m.Doc, comment.Text)
+		}
+	}
+
+	if fi.Comment != nil && len(fi.Comment.List) > 0 {
+		m.Comment = make([]string, 0, len(fi.Comment.List))
+		for _, comment := range fi.Comment.List {
+			m.Comment = append(m.Comment, comment.Text)
+		}
+	}
+
+	directives, err := scanner.ParseDirectives(true, fi.Doc, fi.Comment)
+	if err != nil {
+		return &DirectiveError{Method: m.Name, Err: err}
+	}
+	m.Ignored, m.Timeout, m.Span = directives.Ignore, directives.Timeout, directives.Span
+
+	return nil
+}
+
+// checkTags returns an error if a //ddtrace:tag directive of the method names
+// a parameter it doesn't have.
+func (m *Method) checkTags() error {
+	for _, tag := range m.Span.Tags {
+		if !m.hasParam(tag.Param) {
+			return &DirectiveError{Method: m.Name, Err: fmt.Errorf("tag %q: no parameter %q", tag.Key, tag.Param)}
+		}
+	}
+	return nil
+}
+
+func (m Method) hasParam(name string) bool {
+	for _, p := range m.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Traced reports whether decorators wrap the method: it accepts a context.Context
+// and isn't annotated with //ddtrace:ignore.
+func (m Method) Traced() bool {
+	return m.AcceptsContext && !m.Ignored
+}
+
+// OperationName returns the span name of the method: the name of its //ddtrace:name
+// directive, or its name prefixed with prefix.
+func (m Method) OperationName(prefix string) string {
+	if m.Span.Name != "" {
+		return m.Span.Name
+	}
+	return prefix + "." + m.Name
+}
+
+// SpanOptions returns the tracer.StartSpanOption arguments, each preceded by a comma,
+// setting the resource, service, type and tags of the method's span. The directives
+// of the method take precedence over those of its interface, whose tags only apply
+// to methods having the tagged parameter.
+func (m Method) SpanOptions(iface scanner.SpanDirectives) string {
+	d := m.Span.Merge(iface)
+
+	var opts []string
+	if d.Resource != "" {
+		opts = append(opts, fmt.Sprintf("tracer.ResourceName(%q)", d.Resource))
+	}
+	if d.Service != "" {
+		opts = append(opts, fmt.Sprintf("tracer.ServiceName(%q)", d.Service))
+	}
+	if d.Type != "" {
+		opts = append(opts, fmt.Sprintf("tracer.SpanType(%q)", d.Type))
+	}
+	for _, tag := range d.Tags {
+		if m.hasParam(tag.Param) {
+			opts = append(opts, fmt.Sprintf("tracer.Tag(%q, %s)", tag.Key, tag.Param))
+		}
+	}
+
+	if len(opts) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(opts, ", ")
+}
+
 // NewParam returns Param struct
 func NewParam(name string, fi *ast.Field, usedNames map[string]bool, printer typePrinter, genericTypes genericTypes, genericParams genericParams) (*Param, error) {
 	typ := fi.Type
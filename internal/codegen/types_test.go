@@ -1,9 +1,17 @@
 package codegen
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/printer"
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
 )
 
 func TestMethod_Declaration(t *testing.T) {
@@ -145,3 +153,116 @@ func TestMethod_ResultsMap(t *testing.T) {
 	}
 	assert.Equal(t, "map[string]interface{}{\n\"s\": s}", m.ResultsMap())
 }
+
+func TestNewMethod_TimeoutDirective(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{
+			name: "no directive",
+			src:  "// Get returns a user\nGet(ctx context.Context) error",
+		},
+		{
+			name:        "doc directive",
+			src:         "//ddtrace:timeout 2s\nGet(ctx context.Context) error",
+			wantTimeout: 2 * time.Second,
+		},
+		{
+			name:        "line comment directive",
+			src:         "Get(ctx context.Context) error //ddtrace:timeout 150ms",
+			wantTimeout: 150 * time.Millisecond,
+		},
+		{
+			name:    "invalid duration",
+			src:     "//ddtrace:timeout soon\nGet(ctx context.Context) error",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := token.NewFileSet()
+			f, err := parser.ParseFile(fs, "iface.go", "package p\nimport \"context\"\ntype I interface {\n"+tt.src+"\n}", parser.ParseComments)
+			require.NoError(t, err)
+
+			it := f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.InterfaceType)
+			m, err := NewMethod("Get", it.Methods.List[0], printer.New(fs, nil, ""), nil, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTimeout, m.Timeout)
+		})
+	}
+}
+
+func TestNewMethod_SpanDirectives(t *testing.T) {
+	iface := scanner.SpanDirectives{
+		Service: "users-db",
+		Tags:    []scanner.SpanTag{{Key: "tenant", Param: "tenantID"}},
+	}
+
+	tests := []struct {
+		name       string
+		src        string
+		wantTraced bool
+		wantOp     string
+		wantOpts   string
+		wantErr    string
+	}{
+		{
+			name:       "no directive",
+			src:        "Get(ctx context.Context, id string) error",
+			wantTraced: true,
+			wantOp:     "Users.Get",
+			wantOpts:   `, tracer.ServiceName("users-db")`,
+		},
+		{
+			name:       "span directives",
+			src:        "//ddtrace:name users.get\n//ddtrace:resource GET /users\n//ddtrace:type db\n//ddtrace:tag user.id=id\nGet(ctx context.Context, tenantID, id string) error",
+			wantTraced: true,
+			wantOp:     "users.get",
+			wantOpts:   `, tracer.ResourceName("GET /users"), tracer.ServiceName("users-db"), tracer.SpanType("db"), tracer.Tag("tenant", tenantID), tracer.Tag("user.id", id)`,
+		},
+		{
+			name:     "ignored",
+			src:      "//ddtrace:ignore\nGet(ctx context.Context, id string) error",
+			wantOp:   "Users.Get",
+			wantOpts: `, tracer.ServiceName("users-db")`,
+		},
+		{
+			name:    "tag of unknown parameter",
+			src:     "//ddtrace:tag user.id=userID\nGet(ctx context.Context, id string) error",
+			wantErr: `"Get": tag "user.id": no parameter "userID"`,
+		},
+		{
+			name:    "unknown directive",
+			src:     "Get(ctx context.Context, id string) error //ddtrace:cache",
+			wantErr: `"Get": "//ddtrace:cache": unknown directive`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := token.NewFileSet()
+			f, err := parser.ParseFile(fs, "iface.go", "package p\nimport \"context\"\ntype I interface {\n"+tt.src+"\n}", parser.ParseComments)
+			require.NoError(t, err)
+
+			it := f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.InterfaceType)
+			m, err := NewMethod("Get", it.Methods.List[0], printer.New(fs, nil, ""), nil, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTraced, m.Traced())
+			assert.Equal(t, tt.wantOp, m.OperationName("Users"))
+			assert.Equal(t, tt.wantOpts, m.SpanOptions(iface))
+		})
+	}
+}
@@ -4,14 +4,27 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/mod/modfile"
 	"gopkg.in/yaml.v3"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/workspace"
 )
 
 const FileName = ".ddtrace.yaml"
 
+// Discovery modes select which interfaces of a package decorators are generated for.
+const (
+	// DiscoveryAll generates every interface not annotated with //ddtrace:ignore (default).
+	DiscoveryAll = "all"
+
+	// DiscoveryAnnotated generates only interfaces annotated with //ddtrace:trace
+	// or listed in PackageConfig.Interfaces.
+	DiscoveryAnnotated = "annotated"
+)
+
 // Config represents the top-level .ddtrace.yaml configuration.
 type Config struct {
 	// Output is the default output subdirectory relative to each source package (default: "trace").
@@ -27,6 +40,40 @@ type Config struct {
 	// The output directory (e.g. "trace") is always excluded automatically.
 	Exclude []string `yaml:"exclude"`
 
+	// Metrics enables call count, error count and duration metrics in generated decorators
+	// (reported to the sink set via tracing.SetDefaultMetricsSink).
+	Metrics bool `yaml:"metrics"`
+
+	// Logging additionally generates a <Interface>WithLogging decorator (log/slog) for every interface.
+	Logging bool `yaml:"logging"`
+
+	// PointerReceivers generates decorators with pointer receivers whose constructors return pointers.
+	PointerReceivers bool `yaml:"pointer-receivers"`
+
+	// Registry generates a registry file per output package with Wrap<Interface> and All functions.
+	Registry bool `yaml:"registry"`
+
+	// Adapters lists dependency injection adapters ("fx", "wire") generated next to the registry.
+	// Listing an adapter enables the registry.
+	Adapters []string `yaml:"adapters"`
+
+	// Tags are the build tags source files are matched against, in addition to GOOS and GOARCH.
+	Tags []string `yaml:"tags"`
+
+	// BuildConstraints copies the build constraint of each source file, including the GOOS
+	// and GOARCH of its name, into the file generated from it.
+	BuildConstraints bool `yaml:"build-constraints"`
+
+	// Discovery is the discovery mode, DiscoveryAll or DiscoveryAnnotated.
+	Discovery string `yaml:"discovery"`
+
+	// Filter selects the discovered interfaces decorators are generated for.
+	Filter FilterConfig `yaml:"filter"`
+
+	// Loader selects how interface method sets are resolved: "ast" (default) parses the
+	// sources, "types" type-checks the packages with go/types.
+	Loader string `yaml:"loader"`
+
 	// Packages maps package import paths (or patterns ending in /...) to per-package config.
 	Packages map[string]*PackageConfig `yaml:"packages"`
 }
@@ -36,11 +83,85 @@ type PackageConfig struct {
 	// Output overrides the global output subdirectory for this package.
 	Output string `yaml:"output"`
 
+	// Metrics overrides the global metrics setting for this package.
+	Metrics *bool `yaml:"metrics"`
+
+	// Logging overrides the global logging decorator setting for this package.
+	Logging *bool `yaml:"logging"`
+
+	// PointerReceivers overrides the global pointer-receivers setting for this package.
+	PointerReceivers *bool `yaml:"pointer-receivers"`
+
+	// BuildConstraints overrides the global build-constraints setting for this package.
+	BuildConstraints *bool `yaml:"build-constraints"`
+
+	// Registry overrides the global registry setting for this package.
+	Registry *bool `yaml:"registry"`
+
+	// Adapters overrides the global dependency injection adapters for this package.
+	Adapters []string `yaml:"adapters"`
+
+	// Discovery overrides the global discovery mode for this package.
+	Discovery string `yaml:"discovery"`
+
+	// Filter overrides the global filter settings that it sets for this package.
+	Filter FilterConfig `yaml:"filter"`
+
+	// Decorators is the default decorator chain for interfaces of this package
+	// that don't declare their own (see InterfaceConfig.Decorators).
+	Decorators []string `yaml:"decorators"`
+
 	// Interfaces maps interface names to per-interface config.
 	// If nil/empty, all discovered interfaces are included.
 	Interfaces map[string]*InterfaceConfig `yaml:"interfaces"`
 }
 
+// FilterConfig selects the discovered interfaces decorators are generated for.
+// Interfaces listed in PackageConfig.Interfaces are generated regardless of it.
+type FilterConfig struct {
+	// Include lists regular expressions; if set, only interfaces whose name matches one are generated.
+	// Expressions match whole names.
+	Include []string `yaml:"include"`
+
+	// Exclude lists regular expressions matching the whole names of interfaces to skip.
+	Exclude []string `yaml:"exclude"`
+
+	// OnlyWithContextMethods skips interfaces without a method accepting a context.Context.
+	OnlyWithContextMethods *bool `yaml:"only-with-context-methods"`
+
+	// MinMethods skips interfaces with fewer methods, including those of embedded interfaces.
+	MinMethods *int `yaml:"min-methods"`
+
+	// ExportedOnly skips unexported interfaces.
+	ExportedOnly *bool `yaml:"exported-only"`
+
+	// ExcludeEmbeddingOnly skips interfaces that declare no method and only embed other interfaces.
+	ExcludeEmbeddingOnly *bool `yaml:"exclude-embedding-only"`
+}
+
+// merge returns f with the settings it doesn't set taken from defaults.
+func (f FilterConfig) merge(defaults FilterConfig) FilterConfig {
+	if f.Include == nil {
+		f.Include = defaults.Include
+	}
+	if f.Exclude == nil {
+		f.Exclude = defaults.Exclude
+	}
+	if f.OnlyWithContextMethods == nil {
+		f.OnlyWithContextMethods = defaults.OnlyWithContextMethods
+	}
+	if f.MinMethods == nil {
+		f.MinMethods = defaults.MinMethods
+	}
+	if f.ExportedOnly == nil {
+		f.ExportedOnly = defaults.ExportedOnly
+	}
+	if f.ExcludeEmbeddingOnly == nil {
+		f.ExcludeEmbeddingOnly = defaults.ExcludeEmbeddingOnly
+	}
+	return f
+}
+
 // InterfaceConfig holds per-interface generation settings.
 type InterfaceConfig struct {
 	// Ignore skips this interface during generation.
@@ -49,8 +170,25 @@ type InterfaceConfig struct {
 	// DecoratorName overrides the generated decorator struct name.
 	DecoratorName string `yaml:"decorator-name"`
 
-	// SpanPrefix overrides the span name prefix used in tracing.
+	// SpanPrefix overrides the span name prefix used in tracing. It takes precedence over
+	// a //ddtrace:name directive on the interface.
 	SpanPrefix string `yaml:"span-prefix"`
+
+	// LogArgs lists method parameter names included in logging decorator records.
+	// Parameters not listed are never logged.
+	LogArgs []string `yaml:"log-args"`
+
+	// Timeout is the default deadline applied by the timeout decorator to context-accepting methods.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// MethodTimeouts overrides the deadline per method name. It takes precedence over
+	// //ddtrace:timeout method comments, which take precedence over Timeout.
+	MethodTimeouts map[string]time.Duration `yaml:"method-timeouts"`
+
+	// Decorators lists decorator kinds (tracing, logging, timeout, retry, circuit-breaker), outermost first.
+	// Each listed decorator type is generated along with a New<Interface>Chain constructor
+	// composing them in this order.
+	Decorators []string `yaml:"decorators"`
 }
 
 // ResolvedPackage is a single package to process after pattern expansion.
@@ -122,7 +260,7 @@ func (c *Config) ResolvePackages() ([]ResolvedPackage, error) {
 		merged := c.mergePackageConfig(pkgCfg)
 
 		if strings.HasSuffix(pattern, "/...") {
-			paths, err := expandWildcardPackages(pattern)
+			paths, err := ExpandPattern(pattern)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to resolve pattern %q", pattern)
 			}
@@ -167,11 +305,38 @@ func (c *Config) mergePackageConfig(pkgCfg *PackageConfig) PackageConfig {
 	if merged.Output == "" {
 		merged.Output = c.Output
 	}
+	if merged.Metrics == nil {
+		metrics := c.Metrics
+		merged.Metrics = &metrics
+	}
+	if merged.Logging == nil {
+		logging := c.Logging
+		merged.Logging = &logging
+	}
+	if merged.PointerReceivers == nil {
+		pointerReceivers := c.PointerReceivers
+		merged.PointerReceivers = &pointerReceivers
+	}
+	if merged.BuildConstraints == nil {
+		buildConstraints := c.BuildConstraints
+		merged.BuildConstraints = &buildConstraints
+	}
+	if merged.Registry == nil {
+		registry := c.Registry
+		merged.Registry = &registry
+	}
+	if merged.Adapters == nil {
+		merged.Adapters = c.Adapters
+	}
+	if merged.Discovery == "" {
+		merged.Discovery = c.Discovery
+	}
+	merged.Filter = merged.Filter.merge(c.Filter)
 	return merged
 }
 
-// findModuleRoot walks up from cwd to find go.mod and returns (module path, directory).
-func findModuleRoot() (modulePath string, rootDir string, err error) {
+// FindModule walks up from cwd to find go.mod and returns (module path, directory).
+func FindModule() (modulePath string, rootDir string, err error) {
 	dir, err := os.Getwd()
 	if err != nil {
 		return "", "", errors.Wrap(err, "failed to get working directory")
@@ -197,55 +362,18 @@ func findModuleRoot() (modulePath string, rootDir string, err error) {
 	return "", "", errors.New("go.mod not found")
 }
 
-// expandWildcardPackages finds all Go packages under the given import path prefix
+// ExpandPattern finds all Go packages under the import path prefix of a "/..." pattern
+// in the main modules of the workspace of the working directory (see workspace.Load)
 // by walking the filesystem instead of using `go list`.
-func expandWildcardPackages(pattern string) ([]string, error) {
-	prefix := strings.TrimSuffix(pattern, "/...")
-
-	modulePath, rootDir, err := findModuleRoot()
+func ExpandPattern(pattern string) ([]string, error) {
+	dir, err := os.Getwd()
 	if err != nil {
-		return nil, err
-	}
-
-	if !strings.HasPrefix(prefix, modulePath) {
-		return nil, errors.Errorf("pattern %q is outside module %q", pattern, modulePath)
+		return nil, errors.Wrap(err, "failed to get working directory")
 	}
 
-	relPath := strings.TrimPrefix(prefix, modulePath)
-	relPath = strings.TrimPrefix(relPath, "/")
-	searchDir := filepath.Join(rootDir, relPath)
-
-	var paths []string
-	err = filepath.WalkDir(searchDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil // skip inaccessible directories
-		}
-		if !d.IsDir() {
-			return nil
-		}
-		// skip hidden directories and testdata
-		name := d.Name()
-		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
-			return filepath.SkipDir
-		}
-		// check if directory contains .go files
-		entries, err := os.ReadDir(path)
-		if err != nil {
-			return nil
-		}
-		for _, e := range entries {
-			if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") && !strings.HasSuffix(e.Name(), "_test.go") {
-				rel, _ := filepath.Rel(rootDir, path)
-				importPath := modulePath + "/" + filepath.ToSlash(rel)
-				paths = append(paths, importPath)
-				break
-			}
-		}
-		return nil
-	})
+	ws, err := workspace.Load(dir)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to walk %s", searchDir)
+		return nil, err
 	}
-
-	return paths, nil
+	return ws.ExpandPattern(pattern)
 }
@@ -6,14 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
-	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
 
+	"github.com/tuanvm-tyson/ddtrace/internal/cache"
 	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
 	"github.com/tuanvm-tyson/ddtrace/internal/config"
 	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
@@ -21,6 +23,10 @@ import (
 
 // runWithConfig processes all packages defined in a .ddtrace.yaml config file.
 func (gc *GenerateCommand) runWithConfig(cfg *config.Config, configPath string, stdout io.Writer) error {
+	if err := gc.applyFlags(cfg); err != nil {
+		return err
+	}
+
 	resolved, err := cfg.ResolvePackages()
 	if err != nil {
 		return errors.Wrap(err, "failed to resolve packages from config")
@@ -28,83 +34,120 @@ func (gc *GenerateCommand) runWithConfig(cfg *config.Config, configPath string,
 
 	toProcess := resolved
 	if !gc.forceRegenerate {
-		moduleRoot, modulePath, modErr := findModuleRoot(filepath.Dir(configPath))
-		if modErr == nil {
-			configTime := fileModTime(configPath)
-
-			var lastRunTime time.Time
+		if md, err := loadModuleDeps(filepath.Dir(configPath)); err == nil {
+			toProcess = nil
 			for _, rp := range resolved {
-				srcDir := importPathToDir(moduleRoot, modulePath, rp.ImportPath)
-				if srcDir == "" {
-					continue
-				}
-				outDir := filepath.Join(srcDir, rp.Config.Output)
-				if t := newestTraceModTime(outDir); t.After(lastRunTime) {
-					lastRunTime = t
+				srcDir := md.sourceDir(rp.ImportPath)
+				if srcDir == "" || !upToDate(cfg, rp, srcDir, packageOutputDir(srcDir, rp.Config), md) {
+					toProcess = append(toProcess, rp)
 				}
 			}
+		}
+	}
 
-			var filtered []config.ResolvedPackage
-			for _, rp := range resolved {
-				srcDir := importPathToDir(moduleRoot, modulePath, rp.ImportPath)
-				if srcDir == "" {
-					filtered = append(filtered, rp)
-					continue
-				}
-				outDir := filepath.Join(srcDir, rp.Config.Output)
-				newestOutput := newestTraceModTime(outDir)
-
-				if !newestOutput.IsZero() {
-					if sourceNewerThan(srcDir, newestOutput) ||
-						configTime.After(newestOutput) {
-						filtered = append(filtered, rp)
-					}
-					continue
-				}
-
-				if lastRunTime.IsZero() ||
-					sourceNewerThan(srcDir, lastRunTime) ||
-					configTime.After(lastRunTime) {
-					filtered = append(filtered, rp)
-				}
-			}
-			toProcess = filtered
+	for _, rp := range resolved {
+		if !slices.ContainsFunc(toProcess, func(p config.ResolvedPackage) bool { return p.ImportPath == rp.ImportPath }) {
+			gc.report.addPackage(&PackageReport{ImportPath: rp.ImportPath, Status: StatusSkipped, Reason: "up to date"})
 		}
 	}
 
+	return gc.generatePackages(cfg, configPath, toProcess)
+}
+
+// applyFlags overrides the global settings of cfg with the command line flags.
+// Packages cached with other build tags are dropped.
+func (gc *GenerateCommand) applyFlags(cfg *config.Config) error {
+	if gc.pointerReceivers {
+		cfg.PointerReceivers = true
+	}
+	if gc.buildConstraints {
+		cfg.BuildConstraints = true
+	}
+	if gc.tags != "" {
+		cfg.Tags = splitTags(gc.tags)
+	}
+	if gc.loader != "" {
+		cfg.Loader = gc.loader
+	}
+	if err := checkLoader(cfg.Loader); err != nil {
+		return errors.Wrap(err, "invalid config")
+	}
+
+	if gc.pkgCache != nil && !slices.Equal(gc.pkgCache.Tags(), cfg.Tags) {
+		gc.fset, gc.pkgCache = nil, nil
+	}
+	return nil
+}
+
+// splitTags splits a -tags flag value. Tags are separated by commas, or by spaces in the legacy syntax.
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// generatePackages generates decorators for toProcess concurrently and
+// returns the errors of every package sorted by import path.
+func (gc *GenerateCommand) generatePackages(cfg *config.Config, configPath string, toProcess []config.ResolvedPackage) error {
 	if len(toProcess) == 0 {
 		return nil
 	}
 
-	moduleRoot, modulePath, modErr := findModuleRoot(filepath.Dir(configPath))
-	if modErr != nil {
-		return errors.Wrap(modErr, "failed to find module root")
+	md, err := loadModuleDeps(filepath.Dir(configPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to find module root")
 	}
 
 	pkgMap := make(map[string]*packages.Package, len(toProcess))
+	loadErrs := map[string]error{}
 	for _, rp := range toProcess {
-		dir := importPathToDir(moduleRoot, modulePath, rp.ImportPath)
+		dir := md.sourceDir(rp.ImportPath)
 		if dir == "" {
 			continue
 		}
-		pkg, err := scanner.BuildFromDir(rp.ImportPath, dir)
+		pkg, err := scanner.BuildFromDir(rp.ImportPath, dir, cfg.Tags)
 		if err != nil {
+			if !errors.Is(err, scanner.ErrPackageNotFound) {
+				loadErrs[rp.ImportPath] = err
+			}
 			continue
 		}
 		pkgMap[rp.ImportPath] = pkg
 	}
 
-	sharedFS := token.NewFileSet()
-	pkgCache := codegen.NewPackageCache()
+	if gc.pkgCache == nil {
+		gc.fset, gc.pkgCache = token.NewFileSet(), codegen.NewPackageCache(cfg.Tags)
+	}
+	sharedFS, pkgCache := gc.fset, gc.pkgCache
 	pkgCache.Seed(pkgMap)
 
-	headerTmpl, err := template.New("header").Funcs(helperFuncs).Parse(minimalHeaderTemplate)
-	if err != nil {
-		return errors.Wrap(err, "failed to parse header template")
+	if gc.cache {
+		dir, err := cache.DefaultDir()
+		if err != nil {
+			return errors.Wrap(err, "failed to locate cache directory")
+		}
+
+		store := cache.NewStore(dir, cfg.Tags, md.cacheHash)
+		pkgCache.UseStore(store)
+		defer func() {
+			hits, misses := store.Stats()
+			gc.report.Cache = &CacheReport{Hits: hits, Misses: misses}
+		}()
 	}
-	bodyTmpl, err := template.New("body").Funcs(helperFuncs).Parse(datadogTemplate)
+
+	var typed map[string]*packages.Package
+	var fallbacks map[string]string
+	if cfg.Loader == LoaderTypes {
+		sources := make([]*packages.Package, 0, len(pkgMap))
+		for _, pkg := range pkgMap {
+			sources = append(sources, pkg)
+		}
+		if typed, fallbacks, err = loadTyped(sharedFS, sources, cfg.Tags); err != nil {
+			return err
+		}
+	}
+
+	headerTmpl, bodyTmpls, err := parseTemplates()
 	if err != nil {
-		return errors.Wrap(err, "failed to parse body template")
+		return err
 	}
 
 	workers := runtime.NumCPU()
@@ -114,18 +157,25 @@ func (gc *GenerateCommand) runWithConfig(cfg *config.Config, configPath string,
 	sem := make(chan struct{}, workers)
 
 	var (
-		wg       sync.WaitGroup
-		mu       sync.Mutex
-		firstErr error
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		pkgErr = map[string]error{}
+		failed atomic.Bool
 	)
 
 	for _, rp := range toProcess {
-		sourcePkg, ok := pkgMap[rp.ImportPath]
-		if !ok {
+		if err, ok := loadErrs[rp.ImportPath]; ok {
+			gc.report.addPackage(&PackageReport{ImportPath: rp.ImportPath, Status: StatusError, Errors: []string{err.Error()}})
+			mu.Lock()
+			pkgErr[rp.ImportPath] = errors.Wrapf(err, "failed to load package %s", rp.ImportPath)
+			mu.Unlock()
+			failed.Store(true)
 			continue
 		}
 
-		if len(sourcePkg.GoFiles) == 0 && len(sourcePkg.CompiledGoFiles) == 0 {
+		sourcePkg, ok := pkgMap[rp.ImportPath]
+		if !ok || (len(sourcePkg.GoFiles) == 0 && len(sourcePkg.CompiledGoFiles) == 0) {
+			gc.report.addPackage(&PackageReport{ImportPath: rp.ImportPath, Status: StatusSkipped, Reason: "no Go files found in module"})
 			continue
 		}
 
@@ -135,80 +185,169 @@ func (gc *GenerateCommand) runWithConfig(cfg *config.Config, configPath string,
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			if err := gc.processPackage(rp, cfg, sourcePkg, headerTmpl, bodyTmpl, sharedFS, pkgCache); err != nil {
-				mu.Lock()
-				if firstErr == nil {
-					firstErr = errors.Wrapf(err, "failed to generate for package %s", rp.ImportPath)
+			if gc.failFast && failed.Load() {
+				gc.report.addPackage(&PackageReport{ImportPath: rp.ImportPath, Status: StatusSkipped, Reason: "canceled by --fail-fast"})
+				return
+			}
+
+			pr := &PackageReport{ImportPath: rp.ImportPath, Status: StatusProcessed, Fallback: fallbacks[rp.ImportPath]}
+			gc.report.addPackage(pr)
+
+			if err := gc.processPackage(rp, cfg, sourcePkg, typed[rp.ImportPath], headerTmpl, bodyTmpls, sharedFS, pkgCache, md, pr); err != nil {
+				failed.Store(true)
+
+				var errs errorList
+				for _, e := range flatten(err) {
+					pr.Errors = append(pr.Errors, e.Error())
+					errs = append(errs, errors.Wrapf(e, "failed to generate for package %s", rp.ImportPath))
 				}
+				pr.Status = StatusError
+
+				mu.Lock()
+				pkgErr[rp.ImportPath] = errs.err()
 				mu.Unlock()
 			}
 		}(rp, sourcePkg)
 	}
 
 	wg.Wait()
-	return firstErr
+
+	var errs errorList
+	for _, err := range sortedErrors(pkgErr) {
+		errs = append(errs, flatten(err)...)
+	}
+	if gc.failFast && len(errs) > 0 {
+		return errs[0]
+	}
+	return errs.err()
 }
 
 // processPackage generates tracing decorators for a single package from config.
+// typedPackage is the type-checked source package when the types loader is used, or nil.
 func (gc *GenerateCommand) processPackage(
 	rp config.ResolvedPackage,
 	cfg *config.Config,
 	sourcePackage *packages.Package,
+	typedPackage *packages.Package,
 	headerTmpl *template.Template,
-	bodyTmpl *template.Template,
+	bodyTmpls map[string]*template.Template,
 	sharedFS *token.FileSet,
 	pkgCache *codegen.PackageCache,
+	md *moduleDeps,
+	pr *PackageReport,
 ) error {
 	astPkg, err := scanner.AST(sharedFS, sourcePackage)
 	if err != nil {
 		return errors.Wrap(err, "failed to parse source package AST")
 	}
 
-	fileGroups, err := scanner.ScanPackage(astPkg)
+	fileGroups, err := scanner.ScanPackageAll(astPkg)
 	if err != nil {
 		return errors.Wrap(err, "failed to scan interfaces")
 	}
 
-	if len(fileGroups) == 0 {
-		return nil
-	}
+	srcDir := scanner.Dir(sourcePackage)
+	outDir := packageOutputDir(srcDir, rp.Config)
+	dstPackage := outputPackage(sourcePackage, srcDir, outDir)
 
-	fileGroups = filterInterfaces(fileGroups, rp.Config)
+	sel, err := newInterfaceSelection(rp.Config, dstPackage.PkgPath == sourcePackage.PkgPath, interfaceMethods(sharedFS, sourcePackage, astPkg, typedPackage, pkgCache))
+	if err != nil {
+		return err
+	}
 
+	fileGroups = selectInterfaces(fileGroups, sel)
 	if len(fileGroups) == 0 {
 		return nil
 	}
 
-	srcDir := scanner.Dir(sourcePackage)
-	outDir := rp.Config.Output
-	if !filepath.IsAbs(outDir) {
-		outDir = filepath.Join(srcDir, outDir)
-	}
-
 	if err := gc.fs.MkdirAll(outDir, os.ModePerm); err != nil {
 		return errors.Wrap(err, "failed to create output directory")
 	}
 
-	outPkgName := filepath.Base(outDir)
-	dstPackage := &packages.Package{Name: outPkgName}
-
 	noGenerate := cfg.NoGenerate
 
+	var (
+		registry   []registryInterface
+		errs       errorList
+		outputDeps = map[string][]string{}
+	)
 	wroteGoGenerate := false
 	for _, fg := range fileGroups {
 		outFileName := strings.TrimSuffix(fg.FileName, ".go") + TraceSuffix
 		outFilePath := filepath.Join(outDir, outFileName)
 
-		includeGoGenerate := !noGenerate && !wroteGoGenerate
+		// The registry files would overwrite the decorators, or remove them when disabled.
+		if slices.Contains(registryFiles(), outFileName) {
+			errs = append(errs, errors.Errorf("failed to generate for %s: %s is reserved for the registry, rename the source file", fg.FileName, outFileName))
+			if gc.failFast {
+				return errs.err()
+			}
+			continue
+		}
 
-		if err := gc.generateFileDecorators(sourcePackage, astPkg, dstPackage, headerTmpl, bodyTmpl, sharedFS, pkgCache, fg, outFilePath, includeGoGenerate, rp.Config.Interfaces); err != nil {
-			return errors.Wrapf(err, "failed to generate for %s", fg.FileName)
+		var goGenerate *generateDirective
+		if !noGenerate && !wroteGoGenerate {
+			goGenerate = &generateDirective{tags: cfg.Tags, loader: cfg.Loader}
 		}
 
-		if includeGoGenerate {
+		generated, err := gc.generateFileDecorators(sourcePackage, astPkg, typedPackage, dstPackage, headerTmpl, bodyTmpls, sharedFS, pkgCache, fg, outFilePath, goGenerate, &rp.Config, sel, pr)
+		if err != nil {
+			for _, e := range flatten(err) {
+				errs = append(errs, errors.Wrapf(e, "failed to generate for %s", fg.FileName))
+			}
+			if gc.failFast {
+				return errs.err()
+			}
+		}
+		registry = append(registry, generated...)
+
+		var deps []string
+		for _, ri := range generated {
+			deps = append(deps, ri.Deps...)
+		}
+		slices.Sort(deps)
+		outputDeps[outFileName] = slices.Compact(deps)
+
+		if goGenerate != nil && len(generated) > 0 {
 			wroteGoGenerate = true
 		}
 	}
 
-	return nil
+	// Skipped interfaces are attempted again when a package they failed to resolve from changes.
+	if skipped := sel.skippedDeps(); len(skipped) > 0 {
+		for output, deps := range outputDeps {
+			deps = append(deps, skipped...)
+			slices.Sort(deps)
+			outputDeps[output] = slices.Compact(deps)
+		}
+	}
+
+	if err := gc.generateRegistry(sourcePackage, dstPackage, outDir, rp.Config, registry, pr); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to generate registry"))
+	}
+
+	if len(errs) == 0 {
+		if err := gc.writeManifest(cfg, rp.Config, srcDir, outDir, md, pr.Files, outputDeps); err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to write manifest"))
+		}
+	}
+
+	return errs.err()
+}
+
+// outputPackage returns the package generated into outDir for the source package in srcDir.
+// Decorators generated into srcDir belong to the source package.
+func outputPackage(sourcePackage *packages.Package, srcDir, outDir string) *packages.Package {
+	if filepath.Clean(outDir) == filepath.Clean(srcDir) {
+		return &packages.Package{Name: sourcePackage.Name, PkgPath: sourcePackage.PkgPath}
+	}
+	return &packages.Package{Name: filepath.Base(outDir)}
+}
+
+// packageOutputDir returns the output directory of the package in srcDir.
+func packageOutputDir(srcDir string, pkgCfg config.PackageConfig) string {
+	if filepath.IsAbs(pkgCfg.Output) {
+		return pkgCfg.Output
+	}
+	return filepath.Join(srcDir, pkgCfg.Output)
 }
@@ -0,0 +1,888 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+const (
+	cliPackage     = "github.com/tuanvm-tyson/ddtrace/internal/cli"
+	servicePackage = "github.com/tuanvm-tyson/ddtrace/internal/generate/testdata/service"
+)
+
+// runConfig runs config-driven generation for cfg and returns the generated
+// files keyed by base name. Nothing is written to disk.
+func runConfig(t *testing.T, cfg *config.Config) map[string]string {
+	t.Helper()
+
+	if cfg.Output == "" {
+		cfg.Output = "trace"
+	}
+
+	written := map[string]string{}
+	cmd := NewGenerateCommand()
+	cmd.forceRegenerate = true
+	cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
+		written[filepath.Base(name)] = string(data)
+		return nil
+	}
+	cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+		return nil
+	}
+	cmd.fs.Remove = func(path string) error {
+		return nil
+	}
+
+	configPath, err := filepath.Abs(filepath.Join("..", "..", config.FileName))
+	require.NoError(t, err)
+
+	require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+	return written
+}
+
+func TestRunWithConfig_Metrics(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name    string
+		global  bool
+		pkg     *bool
+		wantOpt bool
+	}{
+		{name: "disabled by default", wantOpt: false},
+		{name: "global", global: true, wantOpt: true},
+		{name: "package override", pkg: &enabled, wantOpt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := runConfig(t, &config.Config{
+				Metrics: tt.global,
+				Packages: map[string]*config.PackageConfig{
+					cliPackage: {Metrics: tt.pkg},
+				},
+			})
+
+			content, ok := written["command_trace.go"]
+			require.True(t, ok)
+			if tt.wantOpt {
+				assert.Contains(t, content, "tracing.WithMetrics()")
+			} else {
+				assert.NotContains(t, content, "tracing.WithMetrics()")
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_Logging(t *testing.T) {
+	written := runConfig(t, &config.Config{
+		Logging: true,
+		Packages: map[string]*config.PackageConfig{
+			servicePackage: {
+				Interfaces: map[string]*config.InterfaceConfig{
+					"UserService": {LogArgs: []string{"id", "name"}},
+				},
+			},
+		},
+	})
+
+	content, ok := written["service_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "UserServiceWithTracing")
+	assert.Contains(t, content, "type UserServiceWithLogging struct")
+	assert.Contains(t, content, "func NewUserServiceWithLogging(base _sourceService.UserService, opts ...tracing.LoggingOption) UserServiceWithLogging")
+	assert.Contains(t, content, `"log/slog"`)
+	assert.Contains(t, content, `_attrs := []slog.Attr{slog.Any("id", id)}`)
+	assert.Contains(t, content, `_attrs := []slog.Attr{slog.Any("name", name)}`)
+	assert.NotContains(t, content, `slog.Any("password"`)
+	assert.Contains(t, content, `_d._log.LogFinish(ctx, "UserService.GetUser", _start, err, _attrs...)`)
+}
+
+func TestRunWithConfig_DecoratorChain(t *testing.T) {
+	written := runConfig(t, &config.Config{
+		Packages: map[string]*config.PackageConfig{
+			servicePackage: {
+				Interfaces: map[string]*config.InterfaceConfig{
+					"UserService": {Decorators: []string{"tracing", "retry", "circuit-breaker"}},
+				},
+			},
+		},
+	})
+
+	content, ok := written["service_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "type UserServiceWithTracing struct")
+	assert.Contains(t, content, "type UserServiceWithRetry struct")
+	assert.Contains(t, content, "type UserServiceWithCircuitBreaker struct")
+	assert.NotContains(t, content, "UserServiceWithLogging")
+	assert.Contains(t, content, "// NewUserServiceChain wraps base with the decorators configured for UserService,")
+	assert.Contains(t, content, "func NewUserServiceChain(base _sourceService.UserService, opts ...tracing.ChainOption) _sourceService.UserService {")
+
+	breaker := strings.Index(content, "_d = NewUserServiceWithCircuitBreaker(_d, _c.CircuitBreaker...)")
+	retry := strings.Index(content, "_d = NewUserServiceWithRetry(_d, _c.Retry...)")
+	tracing := strings.Index(content, "_d = NewUserServiceWithTracing(_d, _c.Tracing...)")
+	assert.True(t, breaker >= 0 && breaker < retry && retry < tracing, "decorators must be applied innermost first")
+}
+
+func TestRunWithConfig_UnknownDecorator(t *testing.T) {
+	cmd := NewGenerateCommand()
+	cmd.forceRegenerate = true
+	cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+		return nil
+	}
+	cmd.fs.Remove = func(path string) error {
+		return nil
+	}
+
+	configPath, err := filepath.Abs(filepath.Join("..", "..", config.FileName))
+	require.NoError(t, err)
+
+	err = cmd.runWithConfig(&config.Config{
+		Output: "trace",
+		Packages: map[string]*config.PackageConfig{
+			servicePackage: {Decorators: []string{"tracing", "cache"}},
+		},
+	}, configPath, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown decorator "cache" for interface UserService`)
+}
+
+func TestRunWithConfig_Timeout(t *testing.T) {
+	written := runConfig(t, &config.Config{
+		Packages: map[string]*config.PackageConfig{
+			servicePackage: {
+				Interfaces: map[string]*config.InterfaceConfig{
+					"UserService": {
+						Decorators:     []string{"tracing", "timeout"},
+						Timeout:        5 * time.Second,
+						MethodTimeouts: map[string]time.Duration{"CreateUser": 1500 * time.Millisecond},
+					},
+				},
+			},
+		},
+	})
+
+	content, ok := written["service_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "type UserServiceWithTimeout struct")
+	assert.Contains(t, content, `ctx, _cancel := _d._timeout.WithTimeout(ctx, "UserService.GetUser", 2*time.Second)`)
+	assert.Contains(t, content, `ctx, _cancel := _d._timeout.WithTimeout(ctx, "UserService.CreateUser", 1500*time.Millisecond)`)
+	assert.Contains(t, content, "_d = NewUserServiceWithTimeout(_d, _c.Timeout...)")
+}
+
+func TestRunWithConfig_PointerReceivers(t *testing.T) {
+	tests := []struct {
+		name     string
+		pointers bool
+		receiver string
+		ctor     string
+	}{
+		{
+			name:     "value receivers by default",
+			receiver: "func (_d UserServiceWithTracing) GetUser(",
+			ctor:     "tracing.TracingOption) UserServiceWithTracing {",
+		},
+		{
+			name:     "pointer receivers",
+			pointers: true,
+			receiver: "func (_d *UserServiceWithTracing) GetUser(",
+			ctor:     "tracing.TracingOption) *UserServiceWithTracing {",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := runConfig(t, &config.Config{
+				Logging:          true,
+				PointerReceivers: tt.pointers,
+				Packages:         map[string]*config.PackageConfig{servicePackage: {}},
+			})
+
+			content, ok := written["service_trace.go"]
+			require.True(t, ok)
+			assert.Contains(t, content, tt.receiver)
+			assert.Contains(t, content, tt.ctor)
+			assert.Contains(t, content, "var _ _sourceService.UserService = (*UserServiceWithTracing)(nil)")
+			assert.Contains(t, content, "var _ _sourceService.UserService = (*UserServiceWithLogging)(nil)")
+		})
+	}
+}
+
+func TestRunWithConfig_Unwrap(t *testing.T) {
+	written := runConfig(t, &config.Config{
+		Logging:  true,
+		Packages: map[string]*config.PackageConfig{servicePackage: {}},
+	})
+
+	content, ok := written["service_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "func (_d UserServiceWithTracing) Unwrap() any {\n\treturn _d.UserService\n}")
+	assert.Contains(t, content, "func (_d UserServiceWithLogging) Unwrap() any {\n\treturn _d.UserService\n}")
+
+	content, ok = written["wrapper_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "type ConnWithTracing struct")
+	assert.NotContains(t, content, "Unwrap() any {")
+}
+
+func TestRunWithConfig_Registry(t *testing.T) {
+	written := runConfig(t, &config.Config{
+		Packages: map[string]*config.PackageConfig{
+			servicePackage: {
+				Adapters: []string{"fx", "wire"},
+				Interfaces: map[string]*config.InterfaceConfig{
+					"UserService": {Decorators: []string{"tracing", "retry"}},
+					"Conn":        {DecoratorName: "TracedConn"},
+				},
+			},
+		},
+	})
+
+	content, ok := written[RegistryFile]
+	require.True(t, ok)
+	assert.Contains(t, content, "func WrapUserService(base _sourceService.UserService, opts ...tracing.ChainOption) _sourceService.UserService {\n\treturn NewUserServiceChain(base, opts...)\n}")
+	assert.Contains(t, content, "return NewTracedConn(_d, _c.Tracing...)")
+	assert.Contains(t, content, "func All(opts ...tracing.ChainOption) []any {")
+	assert.Contains(t, content, "func(base _sourceService.Conn) _sourceService.Conn { return WrapConn(base, opts...) },")
+
+	content, ok = written["ddtrace_fx_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "return fx.Decorate(All(opts...)...)")
+
+	content, ok = written["ddtrace_wire_trace.go"]
+	require.True(t, ok)
+	assert.Contains(t, content, "var ProviderSet = wire.NewSet(\n\tProvideUserService,\n\tProvideConn,\n)")
+	assert.Contains(t, content, "type UserServiceBase interface {\n\t_sourceService.UserService\n}")
+}
+
+func TestRunWithConfig_RegistryDisabled(t *testing.T) {
+	written := runConfig(t, &config.Config{
+		Packages: map[string]*config.PackageConfig{servicePackage: {}},
+	})
+
+	assert.NotContains(t, written, RegistryFile)
+}
+
+func TestRunWithConfig_RegistrySamePackage(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		config.FileName:      "output: .\nno-generate: true\npackages:\n  example.com/app/service:\n    adapters: [wire]\n",
+		"service/service.go": "package service\n\nimport \"context\"\n\ntype UserService interface {\n\tGetUser(ctx context.Context, id string) error\n}\n",
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+	require.NoError(t, NewGenerateCommand().runWithConfig(cfg, configPath, nil))
+
+	for _, name := range []string{"service_trace.go", RegistryFile, "ddtrace_wire_trace.go"} {
+		content, err := os.ReadFile(filepath.Join(dir, "service", name))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "package service\n", name)
+		assert.NotContains(t, string(content), `"example.com/app/service"`, name)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "service", RegistryFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "func WrapUserService(base UserService, opts ...tracing.ChainOption) UserService {")
+}
+
+func TestRunWithConfig_RegistryReservedName(t *testing.T) {
+	const service = "package service\n\nimport \"context\"\n\ntype %s interface {\n\tGet(ctx context.Context) error\n}\n"
+
+	for _, source := range []string{"ddtrace_registry.go", "ddtrace_fx.go", "ddtrace_wire.go"} {
+		t.Run(source, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+				"service/" + source:  fmt.Sprintf(service, "Reserved"),
+				"service/service.go": fmt.Sprintf(service, "UserService"),
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+			err = NewGenerateCommand().runWithConfig(cfg, configPath, nil)
+			require.Error(t, err)
+
+			output := strings.TrimSuffix(source, ".go") + TraceSuffix
+			assert.Equal(t, "failed to generate for package example.com/app/service: failed to generate for "+source+": "+output+" is reserved for the registry, rename the source file", err.Error())
+			assert.NoFileExists(t, filepath.Join(dir, "service", "trace", output))
+			assert.FileExists(t, filepath.Join(dir, "service", "trace", "service_trace.go"))
+		})
+	}
+}
+
+func TestRunWithConfig_RegistryRemoved(t *testing.T) {
+	tests := []struct {
+		name        string
+		pkgConfig   string
+		wantRemoved []string
+	}{
+		{
+			name:        "registry disabled",
+			pkgConfig:   "{}",
+			wantRemoved: []string{RegistryFile, "ddtrace_fx_trace.go", "ddtrace_wire_trace.go"},
+		},
+		{
+			name:        "adapter removed",
+			pkgConfig:   "\n    adapters: [fx]\n",
+			wantRemoved: []string{"ddtrace_wire_trace.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				config.FileName:                         "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n    adapters: [fx, wire]\n",
+				"service/service.go":                    "package service\n\nimport \"context\"\n\ntype UserService interface {\n\tGetUser(ctx context.Context, id string) error\n}\n",
+				"service/trace/ddtrace_custom_trace.go": "package trace\n",
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+			traceDir := filepath.Join(dir, "service", "trace")
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+			require.NoError(t, NewGenerateCommand().runWithConfig(cfg, configPath, nil))
+			for _, name := range tt.wantRemoved {
+				require.FileExists(t, filepath.Join(traceDir, name))
+			}
+
+			require.NoError(t, os.WriteFile(configPath, []byte("output: trace\nno-generate: true\npackages:\n  example.com/app/service: "+tt.pkgConfig), 0o644))
+			cfg, err = config.Load(configPath)
+			require.NoError(t, err)
+			cmd := NewGenerateCommand()
+			require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+
+			var removed []string
+			for _, f := range cmd.report.Packages[0].Files {
+				if f.Status == StatusRemoved {
+					removed = append(removed, filepath.Base(f.Path))
+				}
+			}
+			assert.ElementsMatch(t, tt.wantRemoved, removed)
+			cmd.report.finish()
+			assert.True(t, cmd.report.Changed)
+			for _, name := range tt.wantRemoved {
+				assert.NoFileExists(t, filepath.Join(traceDir, name))
+			}
+			assert.FileExists(t, filepath.Join(traceDir, "ddtrace_custom_trace.go"))
+			assert.FileExists(t, filepath.Join(traceDir, "service_trace.go"))
+
+			sum, err := os.ReadFile(filepath.Join(traceDir, ManifestFile))
+			require.NoError(t, err)
+			for _, name := range tt.wantRemoved {
+				assert.NotContains(t, string(sum), name)
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_UnknownAdapter(t *testing.T) {
+	cmd := NewGenerateCommand()
+	cmd.forceRegenerate = true
+	cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
+		return nil
+	}
+	cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+		return nil
+	}
+	cmd.fs.Remove = func(path string) error {
+		return nil
+	}
+
+	configPath, err := filepath.Abs(filepath.Join("..", "..", config.FileName))
+	require.NoError(t, err)
+
+	err = cmd.runWithConfig(&config.Config{
+		Output:   "trace",
+		Adapters: []string{"dig"},
+		Packages: map[string]*config.PackageConfig{servicePackage: {}},
+	}, configPath, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown adapter "dig"`)
+}
+
+func TestRunWithConfig_AllErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		failFast bool
+		want     []string
+	}{
+		{
+			name: "every package, file and interface in order",
+			want: []string{
+				"failed to generate for package " + cliPackage + ": failed to generate for command.go:",
+				"failed to generate for package " + servicePackage + ": failed to generate for service.go:",
+				"failed to generate for package " + servicePackage + ": failed to generate for store.go:",
+				"failed to generate for package " + servicePackage + ": failed to generate for store.go:",
+				"failed to generate for package " + servicePackage + ": failed to generate for wrapper.go:",
+			},
+		},
+		{
+			name:     "fail fast",
+			failFast: true,
+			want:     []string{"failed to generate for package "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewGenerateCommand()
+			cmd.forceRegenerate = true
+			cmd.failFast = tt.failFast
+			cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
+				return nil
+			}
+			cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+				return nil
+			}
+
+			configPath, err := filepath.Abs(filepath.Join("..", "..", config.FileName))
+			require.NoError(t, err)
+
+			err = cmd.runWithConfig(&config.Config{
+				Output: "trace",
+				Packages: map[string]*config.PackageConfig{
+					cliPackage:     {Decorators: []string{"tracing", "cache"}},
+					servicePackage: {Decorators: []string{"tracing", "cache"}},
+				},
+			}, configPath, nil)
+			require.Error(t, err)
+
+			lines := strings.Split(err.Error(), "\n")
+			require.Len(t, lines, len(tt.want))
+			for i, want := range tt.want {
+				assert.True(t, strings.HasPrefix(lines[i], want), "line %d: %s", i, lines[i])
+				assert.Contains(t, lines[i], `unknown decorator "cache"`)
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_Cache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	dir := writeModule(t, map[string]string{
+		config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+		"service/service.go": "package service\n\nimport (\n\t\"io\"\n\n\t\"example.com/app/model\"\n)\n\ntype UserService interface {\n\tmodel.Reader\n\tio.Closer\n}\n",
+		"model/model.go":     "package model\n\nimport (\n\t\"context\"\n\n\t\"example.com/app/base\"\n)\n\ntype Reader interface {\n\tbase.Pinger\n\tRead(ctx context.Context) error\n}\n",
+		"base/base.go":       "package base\n\nimport \"context\"\n\ntype Pinger interface {\n\tPing(ctx context.Context) error\n}\n",
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+	tracePath := filepath.Join(dir, "service", "trace", "service_trace.go")
+	sumPath := filepath.Join(dir, "service", "trace", ManifestFile)
+
+	run := func(t *testing.T) (*CacheReport, string, string) {
+		t.Helper()
+
+		cfg, err := config.Load(configPath)
+		require.NoError(t, err)
+
+		cmd := NewGenerateCommand()
+		cmd.cache = true
+		cmd.forceRegenerate = true
+		require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+
+		content, err := os.ReadFile(tracePath)
+		require.NoError(t, err)
+		sum, err := os.ReadFile(sumPath)
+		require.NoError(t, err)
+		return cmd.report.Cache, string(content), string(sum)
+	}
+
+	stats, content, sum := run(t)
+	assert.Equal(t, &CacheReport{Hits: 0, Misses: 3}, stats)
+	assert.Contains(t, content, "func (_d UserServiceWithTracing) Ping(")
+	assert.Contains(t, sum, "dep example.com/app/base h1:")
+
+	stats, cachedContent, cachedSum := run(t)
+	assert.Equal(t, &CacheReport{Hits: 2, Misses: 0}, stats)
+	assert.Equal(t, content, cachedContent)
+	assert.Equal(t, sum, cachedSum)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base", "base.go"), []byte("package base\n\nimport \"context\"\n\ntype Pinger interface {\n\tPing(ctx context.Context) error\n\tHealth(ctx context.Context) error\n}\n"), 0o644))
+
+	stats, content, _ = run(t)
+	assert.Equal(t, &CacheReport{Hits: 1, Misses: 2}, stats)
+	assert.Contains(t, content, "func (_d UserServiceWithTracing) Health(")
+}
+
+func TestRunWithConfig_Workspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	dir := writeModule(t, map[string]string{
+		"go.work":               "go 1.23\n\nuse (\n\t.\n\t./shared\n)\n",
+		config.FileName:         "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n  example.com/shared/...:\n",
+		"service/service.go":    "package service\n\nimport \"example.com/shared/model\"\n\ntype UserService interface {\n\tmodel.Reader\n}\n",
+		"shared/go.mod":         "module example.com/shared\n\ngo 1.23\n",
+		"shared/model/model.go": "package model\n\nimport \"context\"\n\ntype Reader interface {\n\tRead(ctx context.Context) error\n}\n",
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	run := func(t *testing.T) map[string]string {
+		t.Helper()
+
+		cfg, err := config.Load(configPath)
+		require.NoError(t, err)
+
+		cmd := NewGenerateCommand()
+		require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+
+		statuses := map[string]string{}
+		for _, pr := range cmd.report.Packages {
+			statuses[pr.ImportPath] = pr.Status
+		}
+		return statuses
+	}
+
+	assert.Equal(t, map[string]string{"example.com/app/service": StatusProcessed, "example.com/shared/model": StatusProcessed}, run(t))
+	assert.FileExists(t, filepath.Join(dir, "shared", "model", "trace", "model_trace.go"))
+	content, err := os.ReadFile(filepath.Join(dir, "service", "trace", "service_trace.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "func (_d UserServiceWithTracing) Read(")
+	sum, err := os.ReadFile(filepath.Join(dir, "service", "trace", ManifestFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(sum), "dep example.com/shared/model h1:")
+
+	assert.Equal(t, map[string]string{"example.com/app/service": StatusSkipped, "example.com/shared/model": StatusSkipped}, run(t))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "model", "model.go"), []byte("package model\n\nimport \"context\"\n\ntype Reader interface {\n\tRead(ctx context.Context) error\n\tClose(ctx context.Context) error\n}\n"), 0o644))
+	assert.Equal(t, map[string]string{"example.com/app/service": StatusProcessed, "example.com/shared/model": StatusProcessed}, run(t))
+}
+
+func TestRunWithConfig_BuildTags(t *testing.T) {
+	other := "windows"
+	if runtime.GOOS == other {
+		other = "linux"
+	}
+
+	tests := []struct {
+		name      string
+		config    string
+		tags      string
+		want      []string
+		wantBuild map[string]string
+	}{
+		{
+			name:      "default",
+			config:    "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+			want:      []string{"service_" + runtime.GOOS + "_trace.go"},
+			wantBuild: map[string]string{"service_" + runtime.GOOS + "_trace.go": "//go:build " + runtime.GOOS + "\n"},
+		},
+		{
+			name:      "tags flag",
+			config:    "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+			tags:      "integration",
+			want:      []string{"integration_trace.go", "service_" + runtime.GOOS + "_trace.go"},
+			wantBuild: map[string]string{"service_" + runtime.GOOS + "_trace.go": "//go:build " + runtime.GOOS + "\n"},
+		},
+		{
+			name:   "tags and constraints in config",
+			config: "output: trace\nno-generate: true\ntags: [integration]\nbuild-constraints: true\npackages:\n  example.com/app/service:\n",
+			want:   []string{"integration_trace.go", "service_" + runtime.GOOS + "_trace.go"},
+			wantBuild: map[string]string{
+				"integration_trace.go":                  "//go:build integration\n",
+				"service_" + runtime.GOOS + "_trace.go": "//go:build " + runtime.GOOS + "\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iface := "package service\n\nimport \"context\"\n\ntype UserService interface {\n\tGet(ctx context.Context, id string) error\n}\n"
+			dir := writeModule(t, map[string]string{
+				config.FileName: tt.config,
+				"service/service_" + runtime.GOOS + ".go": iface,
+				"service/service_" + other + ".go":        iface,
+				"service/integration.go":                  "//go:build integration\n\npackage service\n\nimport \"context\"\n\ntype Fixture interface {\n\tReset(ctx context.Context) error\n}\n",
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+
+			cmd := NewGenerateCommand()
+			cmd.tags = tt.tags
+			require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+
+			matches, err := filepath.Glob(filepath.Join(dir, "service", "trace", "*_trace.go"))
+			require.NoError(t, err)
+			var got []string
+			for _, m := range matches {
+				got = append(got, filepath.Base(m))
+			}
+			assert.Equal(t, tt.want, got)
+
+			for _, name := range got {
+				content, err := os.ReadFile(filepath.Join(dir, "service", "trace", name))
+				require.NoError(t, err)
+				if want, ok := tt.wantBuild[name]; ok {
+					assert.Contains(t, string(content), want)
+				} else {
+					assert.NotContains(t, string(content), "//go:build")
+				}
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_PackageClauses(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		config.FileName:           "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n  example.com/app/broken:\n",
+		"service/doc_test.go":     "package service_test\n",
+		"service/gen.go":          "//go:build ignore\n\npackage main\n\nfunc main() {}\n",
+		"service/service.go":      "package service\n\nimport \"context\"\n\ntype UserService interface {\n\tGet(ctx context.Context, id string) error\n}\n",
+		"service/service_test.go": "package service_test\n\nimport \"testing\"\n\nfunc TestGet(t *testing.T) {}\n",
+		"broken/a.go":             "package broken\n",
+		"broken/b.go":             "package other\n",
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	cmd := NewGenerateCommand()
+	err = cmd.runWithConfig(cfg, configPath, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load package example.com/app/broken: found packages broken (a.go) and other (b.go)")
+
+	statuses := map[string]string{}
+	for _, pr := range cmd.report.Packages {
+		statuses[pr.ImportPath] = pr.Status
+	}
+	assert.Equal(t, map[string]string{"example.com/app/service": StatusProcessed, "example.com/app/broken": StatusError}, statuses)
+	assert.FileExists(t, filepath.Join(dir, "service", "trace", "service_trace.go"))
+
+	listed, err := listPackages(cfg, configPath)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Contains(t, listed[0].Error, "found packages broken (a.go) and other (b.go)")
+	assert.Empty(t, listed[1].Error)
+}
+
+func TestRunWithConfig_LoadAndGenerateErrors(t *testing.T) {
+	const service = "package %s\n\nimport \"context\"\n\ntype Service interface {\n\tGet(ctx context.Context) error\n}\n"
+
+	files := map[string]string{}
+	var cfgYAML strings.Builder
+	cfgYAML.WriteString("output: trace\nno-generate: true\npackages:\n")
+	var want []string
+	for i := range 4 {
+		failing := fmt.Sprintf("failing%d", i)
+		files[failing+"/service.go"] = fmt.Sprintf(service, failing)
+		fmt.Fprintf(&cfgYAML, "  example.com/app/%s:\n    decorators: [tracing, cache]\n", failing)
+		want = append(want, "failed to generate for package example.com/app/"+failing)
+
+		broken := fmt.Sprintf("zbroken%d", i)
+		files[broken+"/a.go"] = "package " + broken + "\n"
+		files[broken+"/b.go"] = "package other\n"
+		fmt.Fprintf(&cfgYAML, "  example.com/app/%s:\n", broken)
+	}
+	for i := range 4 {
+		want = append(want, fmt.Sprintf("failed to load package example.com/app/zbroken%d", i))
+	}
+	files[config.FileName] = cfgYAML.String()
+
+	dir := writeModule(t, files)
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	cmd := NewGenerateCommand()
+	err = cmd.runWithConfig(cfg, configPath, nil)
+	require.Error(t, err)
+
+	lines := strings.Split(err.Error(), "\n")
+	require.Len(t, lines, len(want))
+	for i, w := range want {
+		assert.True(t, strings.HasPrefix(lines[i], w), "line %d: %s", i, lines[i])
+	}
+	for _, pr := range cmd.report.Packages {
+		assert.Equal(t, StatusError, pr.Status, pr.ImportPath)
+	}
+}
+
+func TestRunWithConfig_InterfaceGenerateError(t *testing.T) {
+	// A template failing at execution time stands for a decorator kind that can't be generated.
+	retry := decoratorTemplates[DecoratorRetry]
+	decoratorTemplates[DecoratorRetry] = `{{ template "missing" }}`
+	t.Cleanup(func() { decoratorTemplates[DecoratorRetry] = retry })
+
+	dir := writeModule(t, map[string]string{
+		config.FileName: `output: trace
+no-generate: true
+packages:
+  example.com/app/service:
+    interfaces:
+      Users:
+        decorators: [retry]
+`,
+		"service/service.go": `package service
+
+import "context"
+
+type Users interface {
+	Get(ctx context.Context) error
+}
+
+type Orders interface {
+	List(ctx context.Context) error
+}
+`,
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	cmd := NewGenerateCommand()
+	err = cmd.runWithConfig(cfg, configPath, nil)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "failed to generate for package example.com/app/service: failed to generate for service.go: failed to generate retry decorator for Users"), err.Error())
+
+	require.Len(t, cmd.report.Packages, 1)
+	pr := cmd.report.Packages[0]
+	assert.Equal(t, StatusError, pr.Status)
+	statuses := map[string]string{}
+	for _, ir := range pr.Interfaces {
+		statuses[ir.Name] = ir.Status
+	}
+	assert.Equal(t, map[string]string{"Users": StatusError, "Orders": StatusGenerated}, statuses)
+
+	// The tracing decorator of Users generated before the failure is left out too.
+	out, err := os.ReadFile(filepath.Join(dir, "service", "trace", "service_trace.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "OrdersWithTracing")
+	assert.NotContains(t, string(out), "UsersWithTracing")
+}
+
+const directiveService = `package service
+
+import "context"
+
+//ddtrace:name users
+//ddtrace:service users-db
+//ddtrace:type db
+type UserService interface {
+	//ddtrace:resource SELECT users
+	//ddtrace:tag user.id=id
+	Get(ctx context.Context, id string) error
+
+	//ddtrace:name users.health
+	//ddtrace:service health
+	Ping(ctx context.Context) error
+
+	//ddtrace:ignore
+	Close(ctx context.Context) error
+}
+
+//ddtrace:name orders
+type OrderService interface {
+	//ddtrace:tag order.id=orderID
+	Create(ctx context.Context, orderID string) error
+}
+`
+
+func TestRunWithConfig_Directives(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		want        []string
+		notWant     []string
+		wantSkipped string
+		wantErr     string
+	}{
+		{
+			name:   "directives",
+			source: directiveService,
+			want: []string{
+				`_d._cfg.StartSpan(ctx, "users.Get", tracer.ResourceName("SELECT users"), tracer.ServiceName("users-db"), tracer.SpanType("db"), tracer.Tag("user.id", id))`,
+				`_d._cfg.StartSpan(ctx, "users.health", tracer.ServiceName("health"), tracer.SpanType("db"))`,
+				`_d._log.LogStart(ctx, "users.Get", _attrs...)`,
+				`_d._cfg.StartSpan(ctx, "order.Create", tracer.Tag("order.id", orderID))`,
+			},
+			notWant: []string{
+				"func (_d UserServiceWithTracing) Close(",
+				"func (_d UserServiceWithLogging) Close(",
+			},
+		},
+		{
+			name:    "tag of unknown parameter",
+			source:  "package service\n\nimport \"context\"\n\ntype UserService interface {\n\t//ddtrace:tag user.id=userID\n\tGet(ctx context.Context, id string) error\n}\n",
+			wantErr: `failed to generate for service.go: failed to parse interface declaration: "Get": tag "user.id": no parameter "userID"`,
+		},
+		{
+			name:    "unknown method directive",
+			source:  "package service\n\nimport \"context\"\n\ntype UserService interface {\n\t//ddtrace:bogus\n\tGet(ctx context.Context, id string) error\n}\n",
+			wantErr: `failed to generate for service.go: failed to parse interface declaration: "Get": "//ddtrace:bogus": unknown directive`,
+		},
+		{
+			name:        "embedded interface not found",
+			source:      "package service\n\nimport (\n\t\"context\"\n\n\t\"example.com/app/missing\"\n)\n\ntype UserService interface {\n\tmissing.Getter\n\tPing(ctx context.Context) error\n}\n",
+			wantSkipped: "failed to parse interface declaration",
+		},
+		{
+			name:    "unknown interface directive",
+			source:  "package service\n\nimport \"context\"\n\n//ddtrace:sampled\ntype UserService interface {\n\tGet(ctx context.Context, id string) error\n}\n",
+			wantErr: `failed to scan interfaces: service.go: interface UserService: "//ddtrace:sampled": unknown directive`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				config.FileName:      "output: trace\nno-generate: true\nlogging: true\npackages:\n  example.com/app/service:\n    interfaces:\n      OrderService:\n        span-prefix: order\n",
+				"service/service.go": tt.source,
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+
+			cmd := NewGenerateCommand()
+			err = cmd.runWithConfig(cfg, configPath, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, cmd.report.Packages, 1)
+			if tt.wantSkipped != "" {
+				require.NotEmpty(t, cmd.report.Packages[0].Interfaces)
+				assert.Equal(t, StatusSkipped, cmd.report.Packages[0].Interfaces[0].Status)
+				assert.Contains(t, cmd.report.Packages[0].Interfaces[0].Reason, tt.wantSkipped)
+				return
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, "service", "trace", "service_trace.go"))
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(content), want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, string(content), notWant)
+			}
+		})
+	}
+}
@@ -1,11 +1,20 @@
 package generate
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"go/token"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
 	"github.com/tuanvm-tyson/ddtrace/internal/config"
 )
 
@@ -13,18 +22,34 @@ import (
 type GenerateCommand struct {
 	cli.BaseCommand
 
-	sourcePkg       string
-	outputDir       string
-	configPath      string
-	noGenerate      bool
-	forceRegenerate bool
+	sourcePkg        string
+	outputDir        string
+	configPath       string
+	noGenerate       bool
+	forceRegenerate  bool
+	failFast         bool
+	pointerReceivers bool
+	buildConstraints bool
+	tags             string
+	loader           string
+	format           string
+
+	watch      bool
+	watchDelay time.Duration
+	cache      bool
 
-	fs fileSystem
+	fs     fileSystem
+	report *Report
+
+	// fset and pkgCache are shared by the runs of a watch session.
+	fset     *token.FileSet
+	pkgCache *codegen.PackageCache
 }
 
 type fileSystem struct {
 	WriteFile func(string, []byte, os.FileMode) error
 	MkdirAll  func(string, os.FileMode) error
+	Remove    func(string) error
 }
 
 // NewGenerateCommand creates GenerateCommand
@@ -33,7 +58,9 @@ func NewGenerateCommand() *GenerateCommand {
 		fs: fileSystem{
 			WriteFile: os.WriteFile,
 			MkdirAll:  os.MkdirAll,
+			Remove:    os.Remove,
 		},
+		report: &Report{},
 	}
 
 	flags := &flag.FlagSet{}
@@ -42,10 +69,18 @@ func NewGenerateCommand() *GenerateCommand {
 	flags.StringVar(&gc.configPath, "config", "", `path to .ddtrace.yaml config file (auto-detected if omitted)`)
 	flags.BoolVar(&gc.noGenerate, "g", false, "don't put //go:generate instruction to the generated code")
 	flags.BoolVar(&gc.forceRegenerate, "force", false, "regenerate all packages regardless of file modification times")
+	flags.StringVar(&gc.format, "format", "text", `output format: "text" or "json" (report of packages, files, interfaces and errors; exits with 3 when files changed)`)
+	flags.BoolVar(&gc.failFast, "fail-fast", false, "stop at the first error instead of reporting the errors of every package and file")
+	flags.BoolVar(&gc.cache, "cache", false, "keep the method sets of interfaces embedded from other packages in a persistent cache (see ddtrace cache)")
+	flags.BoolVar(&gc.watch, "watch", false, "keep running and regenerate packages when their sources or the config change")
+	flags.StringVar(&gc.tags, "tags", "", "comma-separated build tags source files are matched against; in config mode overrides tags")
+	flags.BoolVar(&gc.buildConstraints, "build-constraints", false, "copy the build constraint of each source file into the file generated from it")
+	flags.StringVar(&gc.loader, "loader", "", `how interface method sets are resolved: "ast" (default) or "types" (type-checked with go/types)`)
+	flags.BoolVar(&gc.pointerReceivers, "pointer-receivers", false, "generate decorators with pointer receivers and constructors returning pointers")
 
 	gc.BaseCommand = cli.BaseCommand{
 		Short: "generate tracing decorators for all interfaces in a package",
-		Usage: "[-p package] [-o output_dir] [-g] [--config path] [--force]",
+		Usage: "[-p package] [-o output_dir] [-g] [--config path] [--force] [--fail-fast] [--watch] [--cache] [--pointer-receivers] [-tags tag,...] [--build-constraints] [--loader ast|types] [--format text|json]",
 		Flags: flags,
 	}
 
@@ -57,7 +92,68 @@ func (gc *GenerateCommand) Run(args []string, stdout io.Writer) error {
 	if err := gc.FlagSet().Parse(args); err != nil {
 		return cli.CommandLineError(err.Error())
 	}
+	if gc.format != "text" && gc.format != "json" {
+		return cli.CommandLineError(fmt.Sprintf("unknown format %q", gc.format))
+	}
+	if err := checkLoader(gc.loader); err != nil {
+		return cli.CommandLineError(err.Error())
+	}
+
+	if gc.watch {
+		return gc.watchConfig(stdout)
+	}
+
+	gc.report = &Report{}
+	err := gc.run(stdout)
+	if gc.format != "json" {
+		// Exit codes are reserved to the JSON report: go generate runs gen in text mode
+		// and fails on any non-zero code.
+		return err
+	}
+
+	gc.report.finish()
+	if err != nil && len(gc.report.Errors) == 0 {
+		gc.report.Errors = append(gc.report.Errors, err.Error())
+	}
+	if werr := gc.report.write(stdout); werr != nil {
+		return werr
+	}
+
+	switch code := gc.report.exitCode(); code {
+	case ExitNoop:
+		return nil
+	case ExitChanged:
+		return cli.ExitError{Code: code}
+	default:
+		return cli.ExitError{Code: code, Err: err}
+	}
+}
+
+// watchConfig runs gen --watch until the process is interrupted.
+func (gc *GenerateCommand) watchConfig(stdout io.Writer) error {
+	if gc.sourcePkg != "" {
+		return cli.CommandLineError("--watch requires config mode and can't be combined with -p")
+	}
+	if gc.format == "json" {
+		return cli.CommandLineError("--watch can't be combined with --format json")
+	}
+
+	configPath := gc.configPath
+	if configPath == "" {
+		configPath = config.Find()
+	}
+	if configPath == "" {
+		return errors.Errorf("--watch requires a %s config file", config.FileName)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return gc.runWatch(ctx, configPath, stdout)
+}
 
+// run generates decorators in config-driven or single-package mode.
+func (gc *GenerateCommand) run(stdout io.Writer) error {
 	if gc.sourcePkg != "" {
 		return gc.runSinglePackage()
 	}
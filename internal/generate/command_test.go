@@ -1,6 +1,8 @@
 package generate
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
@@ -9,6 +11,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
 )
 
 func TestNewGenerateCommand(t *testing.T) {
@@ -103,23 +107,44 @@ func TestGenerateCommand_Run_NoGenerateFlag(t *testing.T) {
 }
 
 func TestGenerateCommand_Run_WithGoGenerate(t *testing.T) {
-	var writtenContents []string
+	const directive = "//go:generate ddtrace gen -p github.com/tuanvm-tyson/ddtrace/internal/cli -o ./trace"
 
-	cmd := NewGenerateCommand()
-	cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
-		writtenContents = append(writtenContents, string(data))
-		return nil
-	}
-	cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
-		return nil
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "defaults",
+			want: directive + "\n",
+		},
+		{
+			name: "generation flags",
+			args: []string{"--pointer-receivers", "-tags", "integration,linux", "--build-constraints", "--loader", "types"},
+			want: directive + " --pointer-receivers -tags integration,linux --build-constraints --loader types\n",
+		},
 	}
 
-	err := cmd.Run([]string{"-p", "github.com/tuanvm-tyson/ddtrace/internal/cli"}, nil)
-	require.NoError(t, err)
-
-	require.Len(t, writtenContents, 1)
-	assert.Contains(t, writtenContents[0], "go:generate")
-	assert.Contains(t, writtenContents[0], "ddtrace gen -p")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writtenContents []string
+
+			cmd := NewGenerateCommand()
+			cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
+				writtenContents = append(writtenContents, string(data))
+				return nil
+			}
+			cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+				return nil
+			}
+
+			err := cmd.Run(append([]string{"-p", "github.com/tuanvm-tyson/ddtrace/internal/cli"}, tt.args...), nil)
+			require.NoError(t, err)
+
+			require.Len(t, writtenContents, 1)
+			assert.Contains(t, writtenContents[0], tt.want)
+		})
+	}
 }
 
 func TestGenerateCommand_Run_InvalidPackage(t *testing.T) {
@@ -224,4 +249,115 @@ func NewFooWithTracing() {}
 	assert.NotContains(t, result, "import")
 	assert.Contains(t, result, "FooWithTracing struct")
 	assert.Contains(t, result, "NewFooWithTracing")
+	assert.NotContains(t, result, "Code generated")
+	assert.True(t, strings.HasPrefix(result, "// FooWithTracing implements Foo"))
+}
+
+func TestGenerateCommand_Run_FormatJSON(t *testing.T) {
+	cmd := NewGenerateCommand()
+	cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
+		return nil
+	}
+	cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+		return nil
+	}
+
+	var stdout bytes.Buffer
+	err := cmd.Run([]string{"-p", "github.com/tuanvm-tyson/ddtrace/internal/cli", "--format", "json"}, &stdout)
+
+	var exitErr cli.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, ExitChanged, exitErr.Code)
+	assert.NoError(t, exitErr.Err)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.True(t, report.Changed)
+	assert.Empty(t, report.Errors)
+	require.Len(t, report.Packages, 1)
+
+	pr := report.Packages[0]
+	assert.Equal(t, "github.com/tuanvm-tyson/ddtrace/internal/cli", pr.ImportPath)
+	assert.Equal(t, StatusProcessed, pr.Status)
+	require.Len(t, pr.Files, 1)
+	assert.True(t, strings.HasSuffix(pr.Files[0].Path, "command_trace.go"))
+	assert.Equal(t, StatusWritten, pr.Files[0].Status)
+	assert.Contains(t, pr.Interfaces, InterfaceReport{Name: "Command", File: "command.go", Status: StatusGenerated})
+}
+
+func TestGenerateCommand_Run_FormatJSON_Errors(t *testing.T) {
+	cmd := NewGenerateCommand()
+
+	var stdout bytes.Buffer
+	err := cmd.Run([]string{"-p", "nonexistent/package/path", "--format", "json"}, &stdout)
+
+	var exitErr cli.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, ExitErrors, exitErr.Code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.False(t, report.Changed)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "failed to load source package")
+}
+
+func TestGenerateCommand_Run_FormatText_NoExitCode(t *testing.T) {
+	cmd := NewGenerateCommand()
+	cmd.fs.WriteFile = func(name string, data []byte, perm os.FileMode) error {
+		return nil
+	}
+	cmd.fs.MkdirAll = func(path string, perm os.FileMode) error {
+		return nil
+	}
+
+	err := cmd.Run([]string{"-p", "github.com/tuanvm-tyson/ddtrace/internal/cli"}, &bytes.Buffer{})
+	require.NoError(t, err, "text mode exits with 0 although files changed")
+
+	require.Len(t, cmd.report.Packages, 1)
+	require.Len(t, cmd.report.Packages[0].Files, 1)
+	assert.Equal(t, StatusWritten, cmd.report.Packages[0].Files[0].Status)
+}
+
+func TestGenerateCommand_Run_UnknownFormat(t *testing.T) {
+	cmd := NewGenerateCommand()
+
+	err := cmd.Run([]string{"--format", "xml"}, nil)
+	assert.IsType(t, cli.CommandLineError(""), err)
+}
+
+func TestReport_ExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages []*PackageReport
+		want     int
+	}{
+		{
+			name:     "no-op",
+			packages: []*PackageReport{{ImportPath: "a", Status: StatusSkipped, Reason: "up to date"}, {ImportPath: "b", Status: StatusProcessed, Files: []FileReport{{Path: "b_trace.go", Status: StatusUnchanged}}}},
+			want:     ExitNoop,
+		},
+		{
+			name:     "changed",
+			packages: []*PackageReport{{ImportPath: "a", Status: StatusProcessed, Files: []FileReport{{Path: "a_trace.go", Status: StatusWritten}}}},
+			want:     ExitChanged,
+		},
+		{
+			name: "errors take precedence",
+			packages: []*PackageReport{
+				{ImportPath: "b", Status: StatusError, Errors: []string{"boom"}},
+				{ImportPath: "a", Status: StatusProcessed, Files: []FileReport{{Path: "a_trace.go", Status: StatusWritten}}},
+			},
+			want: ExitErrors,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Report{Packages: tt.packages}
+			r.finish()
+			assert.Equal(t, tt.want, r.exitCode())
+			assert.Equal(t, "a", r.Packages[0].ImportPath)
+		})
+	}
 }
@@ -0,0 +1,66 @@
+package generate
+
+import (
+	"sort"
+	"strings"
+)
+
+// errorList aggregates the errors of a generation run. Its message lists one
+// error per line in the order they were appended.
+type errorList []error
+
+func (l errorList) Error() string {
+	msgs := make([]string, 0, len(l))
+	for _, err := range l {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap allows errors.Is and errors.As to inspect every aggregated error.
+func (l errorList) Unwrap() []error {
+	return l
+}
+
+// err returns nil for an empty list, the only error of a single-element list
+// and the list itself otherwise.
+func (l errorList) err() error {
+	switch len(l) {
+	case 0:
+		return nil
+	case 1:
+		return l[0]
+	default:
+		return l
+	}
+}
+
+// flatten returns the errors aggregated by err, expanding nested lists.
+func flatten(err error) []error {
+	l, ok := err.(errorList)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range l {
+		errs = append(errs, flatten(e)...)
+	}
+	return errs
+}
+
+// sortedErrors returns the errors of errs ordered by key, so the result does
+// not depend on the order packages finished in.
+func sortedErrors(errs map[string]error) errorList {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	l := make(errorList, 0, len(keys))
+	for _, key := range keys {
+		l = append(l, errs[key])
+	}
+	return l
+}
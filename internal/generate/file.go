@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"go/token"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -19,110 +19,331 @@ import (
 	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
 )
 
-// filterInterfaces applies interface-level include/ignore rules from config.
-func filterInterfaces(fileGroups []scanner.FileInterfaces, pkgCfg config.PackageConfig) []scanner.FileInterfaces {
-	if len(pkgCfg.Interfaces) == 0 {
-		return fileGroups
+// newFilter compiles the discovery mode and the filter of a package config.
+func newFilter(pkgCfg config.PackageConfig) (scanner.Filter, error) {
+	var filter scanner.Filter
+
+	switch pkgCfg.Discovery {
+	case "", config.DiscoveryAll:
+	case config.DiscoveryAnnotated:
+		filter.AnnotatedOnly = true
+	default:
+		return filter, errors.Errorf("unknown discovery mode %q, want %q or %q", pkgCfg.Discovery, config.DiscoveryAll, config.DiscoveryAnnotated)
 	}
 
-	var result []scanner.FileInterfaces
-	for _, fg := range fileGroups {
-		var filtered []scanner.InterfaceInfo
-		for _, iface := range fg.Interfaces {
-			ifaceCfg, explicit := pkgCfg.Interfaces[iface.Name]
-			if explicit && ifaceCfg != nil && ifaceCfg.Ignore {
-				continue
+	fc := pkgCfg.Filter
+
+	compile := func(key string, exprs []string) ([]scanner.Pattern, error) {
+		res := make([]scanner.Pattern, 0, len(exprs))
+		for _, expr := range exprs {
+			p, err := scanner.CompilePattern(expr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid filter %s pattern", key)
 			}
-			filtered = append(filtered, iface)
+			res = append(res, p)
+		}
+		return res, nil
+	}
+
+	var err error
+	if filter.Include, err = compile("include", fc.Include); err != nil {
+		return filter, err
+	}
+	if filter.Exclude, err = compile("exclude", fc.Exclude); err != nil {
+		return filter, err
+	}
+	if fc.MinMethods != nil {
+		filter.MinMethods = *fc.MinMethods
+	}
+	filter.OnlyWithContextMethods = fc.OnlyWithContextMethods != nil && *fc.OnlyWithContextMethods
+	filter.ExportedOnly = fc.ExportedOnly != nil && *fc.ExportedOnly
+	filter.ExcludeEmbeddingOnly = fc.ExcludeEmbeddingOnly != nil && *fc.ExcludeEmbeddingOnly
+	return filter, nil
+}
+
+// filteredOut returns the reason filter skips iface, or "" if it's kept. Interfaces
+// listed in the package config are always kept, which opts them in in annotated discovery mode.
+// The method set of iface is resolved when the filter depends on it; failing to resolve it is an error.
+func filteredOut(filter scanner.Filter, iface scanner.InterfaceInfo, pkgCfg config.PackageConfig, methods methodsResolver) (string, error) {
+	if _, explicit := pkgCfg.Interfaces[iface.Name]; explicit {
+		return "", nil
+	}
+
+	var infos []scanner.MethodInfo
+	if filter.NeedsMethods() {
+		ms, _, err := methods(iface.Name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to resolve the methods of interface %s for filters", iface.Name)
+		}
+		for name, m := range ms {
+			infos = append(infos, scanner.MethodInfo{Name: name, AcceptsContext: m.AcceptsContext})
+		}
+	}
+	return filter.Skip(iface, infos), nil
+}
+
+// methodsResolver returns the method set of an interface of a package, including embedded interfaces,
+// and the other packages it was resolved from.
+type methodsResolver func(name string) (map[string]codegen.Method, []string, error)
+
+// interfaceMethods returns a resolver of the method sets of the interfaces of a package.
+// Method sets are resolved with go/types when typedPkg is set, like generation does.
+func interfaceMethods(fs *token.FileSet, pkg *packages.Package, astPkg *scanner.Package, typedPkg *packages.Package, pkgCache *codegen.PackageCache) methodsResolver {
+	return func(name string) (map[string]codegen.Method, []string, error) {
+		return codegen.InterfaceMethods(fs, pkg, astPkg, typedPkg, name, pkgCache)
+	}
+}
+
+// configuredChain returns the configured decorator chain of an interface, outermost first.
+// Interface-level configuration takes precedence over the package default.
+func configuredChain(pkgCfg *config.PackageConfig, ifaceName string) ([]string, error) {
+	if pkgCfg == nil {
+		return nil, nil
+	}
+
+	chain := pkgCfg.Decorators
+	if ic, ok := pkgCfg.Interfaces[ifaceName]; ok && ic != nil && len(ic.Decorators) > 0 {
+		chain = ic.Decorators
+	}
+
+	seen := make(map[string]bool, len(chain))
+	for _, kind := range chain {
+		if _, known := decoratorTemplates[kind]; !known || kind == decoratorChain {
+			return nil, errors.Errorf("unknown decorator %q for interface %s", kind, ifaceName)
 		}
-		if len(filtered) > 0 {
-			result = append(result, scanner.FileInterfaces{
-				FileName:   fg.FileName,
-				Interfaces: filtered,
-			})
+		if seen[kind] {
+			return nil, errors.Errorf("duplicate decorator %q for interface %s", kind, ifaceName)
 		}
+		seen[kind] = true
 	}
-	return result
+
+	return chain, nil
 }
 
-// generateFileDecorators generates tracing decorators for all interfaces in a single source file.
+// decoratorKinds returns the decorator kinds to generate for an interface, in output order.
+// The tracing decorator is always generated; a chain constructor is added when a chain is configured.
+func decoratorKinds(pkgCfg *config.PackageConfig, chain []string) []string {
+	kinds := []string{DecoratorTracing}
+	if pkgCfg != nil && pkgCfg.Logging != nil && *pkgCfg.Logging {
+		kinds = append(kinds, DecoratorLogging)
+	}
+	for _, kind := range chain {
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(chain) > 0 {
+		kinds = append(kinds, decoratorChain)
+	}
+	return kinds
+}
+
+// generateDirective holds the settings the //go:generate line of a generated file passes
+// to ddtrace gen, besides those of the package.
+type generateDirective struct {
+	tags   []string
+	loader string
+}
+
+// generateFileDecorators generates tracing decorators for the interfaces of a single source file
+// sel selects, and returns the interfaces whose decorators were generated. A nil goGenerate omits
+// the //go:generate line. Interfaces failing to generate are left out of the file and their errors are returned along with the others.
 func (gc *GenerateCommand) generateFileDecorators(
 	sourcePackage *packages.Package,
 	sourcePackageAST *scanner.Package,
+	typedPackage *packages.Package,
 	dstPackage *packages.Package,
 	headerTmpl *template.Template,
-	bodyTmpl *template.Template,
+	bodyTmpls map[string]*template.Template,
 	sharedFS *token.FileSet,
 	pkgCache *codegen.PackageCache,
 	fg scanner.FileInterfaces,
 	outFilePath string,
-	includeGoGenerate bool,
-	interfaceConfigs map[string]*config.InterfaceConfig,
-) error {
+	goGenerate *generateDirective,
+	pkgCfg *config.PackageConfig,
+	sel *interfaceSelection,
+	pr *PackageReport,
+) ([]registryInterface, error) {
 	var buf bytes.Buffer
 
-	outPkgName := filepath.Base(filepath.Dir(outFilePath))
+	pointerReceivers := gc.pointerReceivers
+	if pkgCfg != nil && pkgCfg.PointerReceivers != nil {
+		pointerReceivers = *pkgCfg.PointerReceivers
+	}
+
+	buildConstraints := gc.buildConstraints
+	if pkgCfg != nil && pkgCfg.BuildConstraints != nil {
+		buildConstraints = *pkgCfg.BuildConstraints
+	}
 
 	fmt.Fprintf(&buf, "// Code generated by ddtrace. DO NOT EDIT.\n")
 	fmt.Fprintf(&buf, "// source: %s\n", fg.FileName)
 	fmt.Fprintf(&buf, "// ddtrace: http://github.com/tuanvm-tyson/ddtrace\n\n")
-	fmt.Fprintf(&buf, "package %s\n\n", outPkgName)
+	// The _trace suffix hides the GOOS and GOARCH of the source file name, so the
+	// constraint they imply is always kept.
+	constraint := fg.NameConstraint
+	if buildConstraints {
+		constraint = fg.Constraint
+	}
+	if constraint != "" {
+		fmt.Fprintf(&buf, "//go:build %s\n\n", constraint)
+	}
+	fmt.Fprintf(&buf, "package %s\n\n", dstPackage.Name)
 
-	if includeGoGenerate {
-		fmt.Fprintf(&buf, "//go:generate ddtrace gen -p %s -o %s\n\n", sourcePackage.PkgPath, gc.outputDir)
+	if goGenerate != nil {
+		fmt.Fprintf(&buf, "//go:generate ddtrace gen -p %s -o %s", sourcePackage.PkgPath, gc.outputDir)
+		if pointerReceivers {
+			buf.WriteString(" --pointer-receivers")
+		}
+		if len(goGenerate.tags) > 0 {
+			fmt.Fprintf(&buf, " -tags %s", strings.Join(goGenerate.tags, ","))
+		}
+		if buildConstraints {
+			buf.WriteString(" --build-constraints")
+		}
+		if goGenerate.loader != "" {
+			fmt.Fprintf(&buf, " --loader %s", goGenerate.loader)
+		}
+		buf.WriteString("\n\n")
 	}
 
+	var (
+		generated []registryInterface
+		errs      errorList
+	)
 	generatedAny := false
 	for _, iface := range fg.Interfaces {
-		vars := make(map[string]interface{})
-		if interfaceConfigs != nil {
-			if ic, ok := interfaceConfigs[iface.Name]; ok && ic != nil {
+		d, err := sel.decide(iface)
+		if err != nil {
+			pr.Interfaces = append(pr.Interfaces, InterfaceReport{Name: iface.Name, File: fg.FileName, Status: StatusError, Reason: err.Error()})
+			errs = append(errs, err)
+			continue
+		}
+		switch d.Status {
+		case StatusIgnored:
+			continue
+		case StatusSkipped:
+			pr.Interfaces = append(pr.Interfaces, InterfaceReport{Name: iface.Name, File: fg.FileName, Status: StatusSkipped, Reason: d.Reason})
+			continue
+		}
+
+		// Settings of .ddtrace.yaml take precedence over the directives of the interface.
+		vars := map[string]interface{}{
+			"MethodTimeouts":   map[string]time.Duration{},
+			"PointerReceivers": pointerReceivers,
+			"Span":             iface.Span,
+		}
+		if iface.Span.Name != "" {
+			vars["SpanNamePrefix"] = iface.Span.Name
+		}
+		if pkgCfg != nil {
+			if pkgCfg.Metrics != nil && *pkgCfg.Metrics {
+				vars["Metrics"] = true
+			}
+			if ic, ok := pkgCfg.Interfaces[iface.Name]; ok && ic != nil {
 				if ic.DecoratorName != "" {
 					vars["DecoratorName"] = ic.DecoratorName
 				}
 				if ic.SpanPrefix != "" {
 					vars["SpanNamePrefix"] = ic.SpanPrefix
 				}
+				vars["LogArgs"] = ic.LogArgs
+				vars["Timeout"] = ic.Timeout
+				if ic.MethodTimeouts != nil {
+					vars["MethodTimeouts"] = ic.MethodTimeouts
+				}
 			}
 		}
 
-		genOutput, err := gc.generateInterfaceOutput(sourcePackage, sourcePackageAST, dstPackage, headerTmpl, bodyTmpl, sharedFS, pkgCache, iface.Name, outFilePath, vars)
-		if err != nil {
+		chain := d.Chain
+		vars["Decorators"] = chain
+
+		// The decorators of an interface are rendered on their own, so a failing kind
+		// leaves none of them in the file.
+		kinds := decoratorKinds(pkgCfg, chain)
+		var (
+			ifaceBuf bytes.Buffer
+			deps     []string
+			genErr   error
+		)
+		for i, kind := range kinds {
+			genOutput, genDeps, err := gc.generateInterfaceOutput(sourcePackage, sourcePackageAST, typedPackage, dstPackage, headerTmpl, bodyTmpls[kind], sharedFS, pkgCache, iface.Name, outFilePath, vars)
+			if err != nil {
+				genErr = errors.Wrapf(err, "failed to generate %s decorator for %s", kind, iface.Name)
+				break
+			}
+			deps = genDeps
+
+			if !generatedAny && i == 0 {
+				ifaceBuf.WriteString(extractAfterPackage(genOutput))
+			} else {
+				ifaceBuf.WriteString(extractBodyOnly(genOutput))
+			}
+			ifaceBuf.WriteString("\n\n")
+		}
+		if genErr != nil {
+			pr.Interfaces = append(pr.Interfaces, InterfaceReport{Name: iface.Name, File: fg.FileName, Status: StatusError, Reason: genErr.Error()})
+			errs = append(errs, genErr)
 			continue
 		}
 
-		if !generatedAny {
-			buf.WriteString(extractAfterPackage(genOutput))
-		} else {
-			buf.WriteString(extractBodyOnly(genOutput))
-		}
-		buf.WriteString("\n")
+		buf.Write(ifaceBuf.Bytes())
 		generatedAny = true
+		pr.Interfaces = append(pr.Interfaces, InterfaceReport{Name: iface.Name, File: fg.FileName, Status: StatusGenerated})
+		generated = append(generated, registryInterface{
+			Name:    iface.Name,
+			Tracing: tracingDecoratorName(vars, iface.Name),
+			Chain:   len(chain) > 0,
+			Logging: slices.Contains(kinds, DecoratorLogging),
+			Deps:    deps,
+		})
 	}
 
 	if !generatedAny {
-		return nil
+		return nil, errs.err()
+	}
+
+	written, err := gc.writeGenerated(outFilePath, buf.Bytes())
+	if err != nil {
+		errs = append(errs, err)
+		return nil, errs.err()
 	}
+	pr.addFile(outFilePath, written)
+
+	return generated, errs.err()
+}
 
-	processed, err := imports.Process(outFilePath, buf.Bytes(), nil)
+// tracingDecoratorName returns the type name of the tracing decorator generated with vars.
+func tracingDecoratorName(vars map[string]interface{}, ifaceName string) string {
+	if name, ok := vars["DecoratorName"].(string); ok && name != "" {
+		return name
+	}
+	return ifaceName + "WithTracing"
+}
+
+// writeGenerated formats generated code and writes it to path, reporting whether the file changed.
+// A file already holding the same code is left untouched.
+func (gc *GenerateCommand) writeGenerated(path string, content []byte) (bool, error) {
+	processed, err := imports.Process(path, content, nil)
 	if err != nil {
-		return errors.Wrapf(err, "failed to format generated code:\n%s", buf.String())
+		return false, errors.Wrapf(err, "failed to format generated code:\n%s", content)
 	}
 
-	if existing, err := os.ReadFile(outFilePath); err == nil && bytes.Equal(existing, processed) {
-		now := time.Now()
-		os.Chtimes(outFilePath, now, now) //nolint: errcheck
-		return nil
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, processed) {
+		return false, nil
 	}
 
-	return gc.fs.WriteFile(outFilePath, processed, 0664)
+	return true, gc.fs.WriteFile(path, processed, 0664)
 }
 
 // generateInterfaceOutput uses the generator engine to produce a complete
-// formatted Go file for a single interface.
+// formatted Go file for a single interface. It also returns the other packages
+// the interface's method set was resolved from. The method set is resolved with
+// go/types when typedPackage is set, from the AST otherwise.
 func (gc *GenerateCommand) generateInterfaceOutput(
 	sourcePackage *packages.Package,
 	sourcePackageAST *scanner.Package,
+	typedPackage *packages.Package,
 	destinationPackage *packages.Package,
 	headerTmpl *template.Template,
 	bodyTmpl *template.Template,
@@ -131,7 +352,7 @@ func (gc *GenerateCommand) generateInterfaceOutput(
 	interfaceName string,
 	outFilePath string,
 	vars map[string]interface{},
-) (string, error) {
+) (string, []string, error) {
 	if vars == nil {
 		vars = make(map[string]interface{})
 	}
@@ -148,6 +369,7 @@ func (gc *GenerateCommand) generateInterfaceOutput(
 		BodyTemplateParsed:         bodyTmpl,
 		FileSet:                    sharedFS,
 		PackageCache:               pkgCache,
+		SourcePackageTyped:         typedPackage,
 		Funcs:                      helperFuncs,
 		Vars:                       vars,
 		HeaderVars:                 make(map[string]interface{}),
@@ -155,15 +377,15 @@ func (gc *GenerateCommand) generateInterfaceOutput(
 
 	gen, err := codegen.NewGenerator(options)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	var genBuf bytes.Buffer
 	if err := gen.Generate(&genBuf); err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return genBuf.String(), nil
+	return genBuf.String(), gen.Dependencies(), nil
 }
 
 // extractAfterPackage strips the package declaration line (and preceding comments)
@@ -184,12 +406,14 @@ func extractAfterPackage(content string) string {
 	return content
 }
 
-// extractBodyOnly strips the package declaration, imports, and leading comments
-// from generated Go source, returning only struct and method definitions.
+// extractBodyOnly strips the package declaration, imports, and comments preceding the
+// package clause from generated Go source, returning only struct and method definitions
+// along with their doc comments.
 func extractBodyOnly(content string) string {
 	lines := strings.Split(content, "\n")
 	var bodyLines []string
 	inImportBlock := false
+	foundPackage := false
 	foundBody := false
 
 	for _, line := range lines {
@@ -210,7 +434,11 @@ func extractBodyOnly(content string) string {
 		}
 
 		if !foundBody {
-			if trimmed == "" || strings.HasPrefix(trimmed, "package ") || strings.HasPrefix(trimmed, "//") {
+			if strings.HasPrefix(trimmed, "package ") {
+				foundPackage = true
+				continue
+			}
+			if trimmed == "" || (!foundPackage && strings.HasPrefix(trimmed, "//")) {
 				continue
 			}
 			foundBody = true
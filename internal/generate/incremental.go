@@ -1,113 +1,83 @@
 package generate
 
 import (
-	"os"
-	"path/filepath"
+	"io/fs"
+	"os/exec"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/pkg/errors"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/workspace"
 )
 
-// findModuleRoot walks up from startDir to find go.mod and returns the
-// directory containing it (module root) and the module path declared in it.
-func findModuleRoot(startDir string) (rootDir, modulePath string, err error) {
-	dir, err := filepath.Abs(startDir)
-	if err != nil {
-		return "", "", err
-	}
+// moduleDeps locates the packages generated code depends on across the main
+// modules of the workspace, following replace directives and vendor mode.
+type moduleDeps struct {
+	ws *workspace.Workspace
+}
 
-	for {
-		gomod := filepath.Join(dir, "go.mod")
-		data, err := os.ReadFile(gomod)
-		if err == nil {
-			modPath := parseModulePath(data)
-			if modPath != "" {
-				return dir, modPath, nil
-			}
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
-		}
-		dir = parent
+// loadModuleDeps loads the workspace or module containing startDir.
+func loadModuleDeps(startDir string) (*moduleDeps, error) {
+	ws, err := workspace.Load(startDir)
+	if err != nil {
+		return nil, err
 	}
-	return "", "", errors.New("go.mod not found")
+	return &moduleDeps{ws: ws}, nil
 }
 
-// parseModulePath extracts the module path from go.mod contents.
-func parseModulePath(gomod []byte) string {
-	for _, line := range strings.Split(string(gomod), "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(line[len("module "):])
-		}
+// sourceDir returns the directory of a package of a main module, or "" for
+// packages that can't be generated for.
+func (md *moduleDeps) sourceDir(importPath string) string {
+	if m, ok := md.ws.Module(importPath); ok {
+		return m.PackageDir(importPath)
 	}
 	return ""
 }
 
-// importPathToDir converts a fully-qualified Go import path to a filesystem
-// directory by stripping the module prefix and joining with the module root.
-// Returns "" if the import path is not within the module.
-func importPathToDir(moduleRoot, modulePath, importPath string) string {
-	if !strings.HasPrefix(importPath, modulePath) {
-		return ""
+// hash returns the manifest hash of a dependency: the hash of its Go files when it's
+// built from a directory of a main module, of the vendor directory or of a module
+// replaced by a local path, its module version otherwise. Packages outside of any
+// required module, like the standard library, aren't recorded.
+func (md *moduleDeps) hash(importPath string) (hash string, ok bool, err error) {
+	if dir := md.ws.Dir(importPath); dir != "" {
+		// A missing directory hashes like an empty one, so creating it is a change.
+		files, err := goFiles(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", false, err
+		}
+		hash, err := hashFiles(dir, files)
+		return hash, err == nil, err
 	}
-	rel := strings.TrimPrefix(importPath, modulePath)
-	return filepath.Join(moduleRoot, filepath.FromSlash(rel))
-}
 
-// fileModTime returns the modification time of a file, or zero time on error.
-func fileModTime(path string) time.Time {
-	info, err := os.Stat(path)
-	if err != nil {
-		return time.Time{}
+	if version := md.ws.Version(importPath); version != "" {
+		return version, true, nil
 	}
-	return info.ModTime()
+	return "", false, nil
 }
 
-// newestTraceModTime returns the newest modification time among *_trace.go
-// files in dir.
-func newestTraceModTime(dir string) time.Time {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return time.Time{}
+// cacheHash is hash extended to standard library packages, hashed by the Go version
+// they come with, for the persistent method set cache.
+func (md *moduleDeps) cacheHash(importPath string) (string, bool, error) {
+	if hash, ok, err := md.hash(importPath); ok || err != nil {
+		return hash, ok, err
 	}
-	var newest time.Time
-	for _, e := range entries {
-		if e.IsDir() || !strings.HasSuffix(e.Name(), TraceSuffix) {
-			continue
-		}
-		info, err := e.Info()
+
+	if first, _, _ := strings.Cut(importPath, "/"); !strings.Contains(first, ".") {
+		version, err := goVersion()
 		if err != nil {
-			continue
-		}
-		if info.ModTime().After(newest) {
-			newest = info.ModTime()
+			return "", false, err
 		}
+		return "std@" + version, true, nil
 	}
-	return newest
+	return "", false, nil
 }
 
-// sourceNewerThan checks whether any non-test .go file in srcDir has a
-// modification time strictly after threshold.
-func sourceNewerThan(srcDir string, threshold time.Time) bool {
-	entries, err := os.ReadDir(srcDir)
+// goVersion returns the version of the go command used to load packages.
+var goVersion = sync.OnceValues(func() (string, error) {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
 	if err != nil {
-		return true
-	}
-	for _, e := range entries {
-		name := e.Name()
-		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, TestSuffix) {
-			continue
-		}
-		info, err := e.Info()
-		if err != nil {
-			return true
-		}
-		if info.ModTime().After(threshold) {
-			return true
-		}
+		return "", errors.Wrap(err, "failed to get Go version")
 	}
-	return false
-}
+	return strings.TrimSpace(string(out)), nil
+})
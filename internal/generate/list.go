@@ -0,0 +1,282 @@
+package generate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
+)
+
+// Statuses reported by the list command.
+const (
+	// StatusGenerated marks an interface decorators are generated for.
+	StatusGenerated = "generated"
+
+	// StatusTraced marks a method wrapped by the generated decorators.
+	StatusTraced = "traced"
+
+	// StatusPassThrough marks a method forwarded to the wrapped implementation as is.
+	StatusPassThrough = "pass-through"
+
+	// StatusIgnored marks an interface excluded by a directive or the config.
+	StatusIgnored = "ignored"
+
+	// StatusSkipped marks an interface no decorator can be generated for.
+	StatusSkipped = "skipped"
+
+	// StatusError marks a package that could not be loaded, or an interface whose
+	// decorators failed to generate.
+	StatusError = "error"
+)
+
+// ListCommand implements cli.Command interface
+type ListCommand struct {
+	cli.BaseCommand
+
+	configPath string
+	format     string
+}
+
+// NewListCommand creates ListCommand
+func NewListCommand() *ListCommand {
+	lc := &ListCommand{}
+
+	flags := &flag.FlagSet{}
+	flags.StringVar(&lc.configPath, "config", "", `path to .ddtrace.yaml config file (auto-detected if omitted)`)
+	flags.StringVar(&lc.format, "format", "table", `output format: "table" or "json"`)
+
+	lc.BaseCommand = cli.BaseCommand{
+		Short: "list the interfaces and methods the config would generate decorators for",
+		Usage: "[--config path] [--format table|json]",
+		Flags: flags,
+	}
+
+	return lc
+}
+
+// ListedPackage is a package resolved from the config.
+type ListedPackage struct {
+	ImportPath string       `json:"package"`
+	Error      string       `json:"error,omitempty"`
+	Files      []ListedFile `json:"files,omitempty"`
+}
+
+// ListedFile is a source file declaring interfaces.
+type ListedFile struct {
+	Name       string            `json:"file"`
+	Interfaces []ListedInterface `json:"interfaces"`
+}
+
+// ListedInterface is an interface with its generation status.
+type ListedInterface struct {
+	Name    string         `json:"name"`
+	Status  string         `json:"status"`
+	Reason  string         `json:"reason,omitempty"`
+	Methods []ListedMethod `json:"methods,omitempty"`
+}
+
+// ListedMethod is a method of a generated interface with its status.
+type ListedMethod struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Run implements cli.Command interface
+func (lc *ListCommand) Run(args []string, stdout io.Writer) error {
+	if err := lc.FlagSet().Parse(args); err != nil {
+		return cli.CommandLineError(err.Error())
+	}
+	if lc.format != "table" && lc.format != "json" {
+		return cli.CommandLineError(fmt.Sprintf("unknown format %q", lc.format))
+	}
+
+	configPath := lc.configPath
+	if configPath == "" {
+		configPath = config.Find()
+	}
+	if configPath == "" {
+		return errors.Errorf("%s not found", config.FileName)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	listed, err := listPackages(cfg, configPath)
+	if err != nil {
+		return err
+	}
+
+	if lc.format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listed)
+	}
+	return writeListTable(stdout, listed)
+}
+
+// listPackages resolves the packages of cfg and reports the status of their interfaces and methods.
+func listPackages(cfg *config.Config, configPath string) ([]ListedPackage, error) {
+	if err := checkLoader(cfg.Loader); err != nil {
+		return nil, err
+	}
+
+	resolved, err := cfg.ResolvePackages()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve packages from config")
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].ImportPath < resolved[j].ImportPath })
+
+	md, err := loadModuleDeps(filepath.Dir(configPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find module root")
+	}
+
+	pkgMap := make(map[string]*packages.Package, len(resolved))
+	loadErrs := map[string]error{}
+	for _, rp := range resolved {
+		if dir := md.sourceDir(rp.ImportPath); dir != "" {
+			pkg, err := scanner.BuildFromDir(rp.ImportPath, dir, cfg.Tags)
+			switch {
+			case err == nil:
+				pkgMap[rp.ImportPath] = pkg
+			case !errors.Is(err, scanner.ErrPackageNotFound):
+				loadErrs[rp.ImportPath] = err
+			}
+		}
+	}
+
+	fset := token.NewFileSet()
+	pkgCache := codegen.NewPackageCache(cfg.Tags)
+	pkgCache.Seed(pkgMap)
+
+	// Method sets are resolved with the loader gen uses; packages failing to
+	// type-check fall back to their AST, like they do in gen.
+	typed := map[string]*packages.Package{}
+	if cfg.Loader == LoaderTypes && len(pkgMap) > 0 {
+		sources := make([]*packages.Package, 0, len(pkgMap))
+		for _, rp := range resolved {
+			if pkg, ok := pkgMap[rp.ImportPath]; ok {
+				sources = append(sources, pkg)
+			}
+		}
+		if typed, _, err = loadTyped(fset, sources, cfg.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	listed := make([]ListedPackage, 0, len(resolved))
+	for _, rp := range resolved {
+		lp := ListedPackage{ImportPath: rp.ImportPath}
+
+		if err, ok := loadErrs[rp.ImportPath]; ok {
+			lp.Error = err.Error()
+			listed = append(listed, lp)
+			continue
+		}
+
+		pkg, ok := pkgMap[rp.ImportPath]
+		if !ok {
+			lp.Error = "package not found in the main modules of " + md.ws.Root
+			listed = append(listed, lp)
+			continue
+		}
+
+		if lp.Files, err = listFiles(fset, pkg, typed[rp.ImportPath], rp.Config, pkgCache); err != nil {
+			lp.Error = err.Error()
+		}
+		listed = append(listed, lp)
+	}
+
+	return listed, nil
+}
+
+func listFiles(fset *token.FileSet, pkg *packages.Package, typedPkg *packages.Package, pkgCfg config.PackageConfig, pkgCache *codegen.PackageCache) ([]ListedFile, error) {
+	astPkg, err := scanner.AST(fset, pkg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse source package AST")
+	}
+
+	fileGroups, err := scanner.ScanPackageAll(astPkg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan interfaces")
+	}
+
+	srcDir := scanner.Dir(pkg)
+	dstPackage := outputPackage(pkg, srcDir, packageOutputDir(srcDir, pkgCfg))
+
+	sel, err := newInterfaceSelection(pkgCfg, dstPackage.PkgPath == pkg.PkgPath, interfaceMethods(fset, pkg, astPkg, typedPkg, pkgCache))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]ListedFile, 0, len(fileGroups))
+	for _, fg := range fileGroups {
+		lf := ListedFile{Name: fg.FileName}
+		for _, iface := range fg.Interfaces {
+			lf.Interfaces = append(lf.Interfaces, listInterface(sel, iface))
+		}
+		files = append(files, lf)
+	}
+	return files, nil
+}
+
+// listInterface reports the decision of sel for iface. Errors gen fails on are reported as skipped.
+func listInterface(sel *interfaceSelection, iface scanner.InterfaceInfo) ListedInterface {
+	li := ListedInterface{Name: iface.Name}
+
+	d, err := sel.decide(iface)
+	if err != nil {
+		li.Status, li.Reason = StatusSkipped, err.Error()
+		return li
+	}
+	li.Status, li.Reason = d.Status, d.Reason
+
+	for _, name := range sortedMethodNames(d.Methods) {
+		status, reason := methodStatus(d.Methods[name])
+		li.Methods = append(li.Methods, ListedMethod{Name: name, Status: status, Reason: reason})
+	}
+	return li
+}
+
+func writeListTable(w io.Writer, listed []ListedPackage) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tFILE\tINTERFACE\tMETHOD\tSTATUS\tREASON")
+
+	for _, lp := range listed {
+		if lp.Error != "" {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t%s\t%s\n", lp.ImportPath, StatusError, lp.Error)
+		}
+		for _, lf := range lp.Files {
+			for _, li := range lf.Interfaces {
+				if len(li.Methods) == 0 {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t-\t%s\t%s\n", lp.ImportPath, lf.Name, li.Name, li.Status, oneLine(li.Reason))
+				}
+				for _, m := range li.Methods {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", lp.ImportPath, lf.Name, li.Name, m.Name, m.Status, m.Reason)
+				}
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,314 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+func TestListPackages(t *testing.T) {
+	configPath, err := filepath.Abs(filepath.Join("..", "..", config.FileName))
+	require.NoError(t, err)
+
+	listed, err := listPackages(&config.Config{
+		Output: "trace",
+		Packages: map[string]*config.PackageConfig{
+			servicePackage: {
+				Interfaces: map[string]*config.InterfaceConfig{
+					"Conn": {Ignore: true},
+				},
+			},
+			"github.com/tuanvm-tyson/ddtrace/internal/missing": nil,
+		},
+	}, configPath)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	assert.Equal(t, "github.com/tuanvm-tyson/ddtrace/internal/missing", listed[1].ImportPath)
+	assert.Contains(t, listed[1].Error, "package not found")
+
+	service := listed[0]
+	assert.Equal(t, servicePackage, service.ImportPath)
+	assert.Empty(t, service.Error)
+
+	interfaces := map[string]ListedInterface{}
+	for _, lf := range service.Files {
+		for _, li := range lf.Interfaces {
+			interfaces[lf.Name+":"+li.Name] = li
+		}
+	}
+
+	assert.Equal(t, ListedInterface{
+		Name:   "UserService",
+		Status: StatusGenerated,
+		Methods: []ListedMethod{
+			{Name: "CreateUser", Status: StatusTraced},
+			{Name: "GetUser", Status: StatusTraced},
+			{Name: "Name", Status: StatusPassThrough, Reason: "first parameter is not a context.Context"},
+		},
+	}, interfaces["service.go:UserService"])
+
+	tests := []struct {
+		key    string
+		status string
+		reason string
+	}{
+		{key: "store.go:Cache", status: StatusIgnored, reason: "//ddtrace:ignore directive"},
+		{key: "store.go:Empty", status: StatusSkipped, reason: "interface has no methods"},
+		{key: "store.go:internalStore", status: StatusSkipped, reason: "unexported method load"},
+		{key: "wrapper.go:Conn", status: StatusIgnored, reason: "ignore: true in .ddtrace.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			li, ok := interfaces[tt.key]
+			require.True(t, ok)
+			assert.Equal(t, tt.status, li.Status)
+			assert.Equal(t, tt.reason, li.Reason)
+			assert.Empty(t, li.Methods)
+		})
+	}
+}
+
+func TestWriteListTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListTable(&buf, []ListedPackage{
+		{ImportPath: "example.com/missing", Error: "package not found"},
+		{ImportPath: "example.com/svc", Files: []ListedFile{{
+			Name: "svc.go",
+			Interfaces: []ListedInterface{
+				{Name: "Svc", Status: StatusGenerated, Methods: []ListedMethod{{Name: "Do", Status: StatusTraced}}},
+				{Name: "Helper", Status: StatusIgnored, Reason: "//ddtrace:ignore directive"},
+			},
+		}}},
+	}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"PACKAGE", "FILE", "INTERFACE", "METHOD", "STATUS", "REASON"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"example.com/missing", "-", "-", "-", "error", "package", "not", "found"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"example.com/svc", "svc.go", "Svc", "Do", "traced"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"example.com/svc", "svc.go", "Helper", "-", "ignored", "//ddtrace:ignore", "directive"}, strings.Fields(lines[3]))
+}
+
+func TestListCommand_Run_JSON(t *testing.T) {
+	cmd := NewListCommand()
+	var buf bytes.Buffer
+	require.NoError(t, cmd.Run([]string{"--config", filepath.Join("..", "..", "examples", "global", config.FileName), "--format", "json"}, &buf))
+
+	var listed []ListedPackage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, "github.com/tuanvm-tyson/ddtrace/examples/global", listed[0].ImportPath)
+	assert.NotEmpty(t, listed[0].Files)
+}
+
+func TestListCommand_Run_UnknownFormat(t *testing.T) {
+	cmd := NewListCommand()
+	err := cmd.Run([]string{"--format", "xml"}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown format "xml"`)
+}
+
+const filterService = `package service
+
+import (
+	"context"
+	"io"
+)
+
+type UserService interface {
+	Get(ctx context.Context, id string) error
+	Close() error
+}
+
+type Closer interface {
+	io.Closer
+}
+
+type Pinger interface {
+	Ping() error
+}
+
+type MockService interface {
+	Get(ctx context.Context) error
+}
+
+type Store interface {
+	Load(ctx context.Context) error
+}
+
+type helper interface {
+	Do(ctx context.Context) error
+}
+`
+
+func TestListPackages_Filter(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		config.FileName: `output: trace
+no-generate: true
+filter:
+  exclude: ["Mock.*"]
+  only-with-context-methods: true
+  exported-only: true
+  exclude-embedding-only: true
+packages:
+  example.com/app/service:
+    filter:
+      min-methods: 2
+    interfaces:
+      Store:
+`,
+		"service/service.go": filterService,
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	listed, err := listPackages(cfg, configPath)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Empty(t, listed[0].Error)
+	require.Len(t, listed[0].Files, 1)
+
+	reasons := map[string]string{}
+	for _, li := range listed[0].Files[0].Interfaces {
+		reasons[li.Name] = li.Reason
+		if li.Reason == "" {
+			assert.Equal(t, StatusGenerated, li.Status, li.Name)
+		} else {
+			assert.Equal(t, StatusIgnored, li.Status, li.Name)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"UserService": "",
+		"Closer":      "filter: interface only embeds other interfaces",
+		"Pinger":      "filter: fewer than 2 methods",
+		"MockService": "filter: name matches exclude pattern Mock.*",
+		"Store":       "",
+		"helper":      "filter: unexported interface",
+	}, reasons)
+
+	cmd := NewGenerateCommand()
+	require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+	content, err := os.ReadFile(filepath.Join(dir, "service", "trace", "service_trace.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "type UserServiceWithTracing struct")
+	assert.Contains(t, string(content), "type StoreWithTracing struct")
+	assert.NotContains(t, string(content), "PingerWithTracing")
+	assert.NotContains(t, string(content), "CloserWithTracing")
+
+	cfg.Filter.Include = []string{"("}
+	listed, err = listPackages(cfg, configPath)
+	require.NoError(t, err)
+	assert.Contains(t, listed[0].Error, "invalid filter include pattern")
+}
+
+const discoveryService = `package service
+
+import "context"
+
+//ddtrace:trace
+type UserService interface {
+	Get(ctx context.Context, id string) error
+}
+
+type OrderService interface {
+	Create(ctx context.Context) error
+}
+
+type Store interface {
+	Load(ctx context.Context) error
+}
+
+//ddtrace:trace
+//ddtrace:ignore
+type Cache interface {
+	Get(ctx context.Context, key string) error
+}
+`
+
+func TestListPackages_Discovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "all",
+			config: "packages:\n  example.com/app/service:\n",
+			want: map[string]string{
+				"UserService":  "",
+				"OrderService": "",
+				"Store":        "",
+				"Cache":        "//ddtrace:ignore directive",
+			},
+		},
+		{
+			name:   "annotated",
+			config: "discovery: annotated\npackages:\n  example.com/app/service:\n    interfaces:\n      Store:\n        span-prefix: store\n",
+			want: map[string]string{
+				"UserService":  "",
+				"OrderService": "filter: not annotated with //ddtrace:trace",
+				"Store":        "",
+				"Cache":        "//ddtrace:ignore directive",
+			},
+		},
+		{
+			name:   "package overrides global",
+			config: "discovery: annotated\npackages:\n  example.com/app/service:\n    discovery: all\n",
+			want: map[string]string{
+				"UserService":  "",
+				"OrderService": "",
+				"Store":        "",
+				"Cache":        "//ddtrace:ignore directive",
+			},
+		},
+		{
+			name:    "unknown mode",
+			config:  "discovery: explicit\npackages:\n  example.com/app/service:\n",
+			wantErr: `unknown discovery mode "explicit", want "all" or "annotated"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				config.FileName:      "output: trace\n" + tt.config,
+				"service/service.go": discoveryService,
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+
+			listed, err := listPackages(cfg, configPath)
+			require.NoError(t, err)
+			require.Len(t, listed, 1)
+			if tt.wantErr != "" {
+				assert.Equal(t, tt.wantErr, listed[0].Error)
+				return
+			}
+			require.Empty(t, listed[0].Error)
+			require.Len(t, listed[0].Files, 1)
+
+			reasons := map[string]string{}
+			for _, li := range listed[0].Files[0].Interfaces {
+				reasons[li.Name] = li.Reason
+			}
+			assert.Equal(t, tt.want, reasons)
+		})
+	}
+}
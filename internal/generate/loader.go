@@ -0,0 +1,59 @@
+package generate
+
+import (
+	"go/token"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
+)
+
+// Loaders resolving the method sets of interfaces.
+const (
+	// LoaderAST resolves method sets from the parsed sources, guessing the packages of
+	// embedded interfaces from their imports. It is fast and needs no build.
+	LoaderAST = "ast"
+
+	// LoaderTypes type-checks the source packages with go/types, which handles package names
+	// that differ from their directory, dot imports and type aliases.
+	LoaderTypes = "types"
+)
+
+// checkLoader returns an error if loader is neither empty nor a known loader.
+func checkLoader(loader string) error {
+	switch loader {
+	case "", LoaderAST, LoaderTypes:
+		return nil
+	default:
+		return errors.Errorf("unknown loader %q, expected %q or %q", loader, LoaderAST, LoaderTypes)
+	}
+}
+
+// loadTyped type-checks the source packages for the types loader. Packages that fail
+// to type-check are left out and generated from their AST instead; fallbacks maps
+// their import paths to the reason.
+func loadTyped(fs *token.FileSet, sources []*packages.Package, tags []string) (typed map[string]*packages.Package, fallbacks map[string]string, err error) {
+	loaded, err := scanner.LoadTypes(fs, sources, tags)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to type-check packages")
+	}
+
+	typed = make(map[string]*packages.Package, len(sources))
+	fallbacks = map[string]string{}
+	for _, source := range sources {
+		path := source.PkgPath
+		pkg, ok := loaded[path]
+		switch {
+		case !ok:
+			fallbacks[path] = "package was not loaded"
+		case len(pkg.Errors) > 0:
+			fallbacks[path] = pkg.Errors[0].Error()
+		case pkg.Types == nil:
+			fallbacks[path] = "package was not type-checked"
+		default:
+			typed[path] = pkg
+		}
+	}
+	return typed, fallbacks, nil
+}
@@ -0,0 +1,212 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+// loaderService embeds an interface of a dot import through an alias and one of a
+// package whose name differs from its directory, which the AST loader can't resolve.
+const loaderService = `package service
+
+import (
+	"context"
+
+	. "example.com/app/model"
+	"example.com/app/lib/v2"
+)
+
+type Source = Reader
+
+type UserService interface {
+	Source
+	lib.Doer
+	Get(context.Context, ...string) (*lib.Item, error)
+}
+`
+
+func TestRunWithConfig_Loader(t *testing.T) {
+	tests := []struct {
+		name         string
+		loader       string
+		broken       bool
+		wantSkipped  string
+		wantFallback string
+		want         []string
+	}{
+		{
+			name:        "ast",
+			loader:      LoaderAST,
+			wantSkipped: "failed to parse interface declaration",
+		},
+		{
+			name:   "types",
+			loader: LoaderTypes,
+			want: []string{
+				`lib "example.com/app/lib/v2"`,
+				"func (_d UserServiceWithTracing) Read(ctx context.Context, id string) (ba1 []byte, err error)",
+				"func (_d UserServiceWithTracing) Do(ctx context.Context, item lib.Item) (err error)",
+				"func (_d UserServiceWithTracing) Get(ctx context.Context, s1 ...string) (ip1 *lib.Item, err error)",
+			},
+		},
+		{
+			name:         "types falls back to ast",
+			loader:       LoaderTypes,
+			broken:       true,
+			wantSkipped:  "failed to parse interface declaration",
+			wantFallback: "undefined: missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{
+				config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+				"service/service.go": loaderService,
+				"model/model.go":     "package model\n\nimport \"context\"\n\ntype Reader interface {\n\tRead(ctx context.Context, id string) ([]byte, error)\n}\n",
+				"lib/v2/lib.go":      "package lib\n\nimport \"context\"\n\ntype Item struct{}\n\ntype Doer interface {\n\tDo(ctx context.Context, item Item) error\n}\n",
+			}
+			if tt.broken {
+				files["service/broken.go"] = "package service\n\nvar _ = missing\n"
+			}
+			dir := writeModule(t, files)
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+
+			cmd := NewGenerateCommand()
+			cmd.loader = tt.loader
+			require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+
+			require.Len(t, cmd.report.Packages, 1)
+			pr := cmd.report.Packages[0]
+			assert.Contains(t, pr.Fallback, tt.wantFallback)
+			require.Len(t, pr.Interfaces, 1)
+			if tt.wantSkipped != "" {
+				assert.Equal(t, StatusSkipped, pr.Interfaces[0].Status)
+				assert.Contains(t, pr.Interfaces[0].Reason, tt.wantSkipped)
+				return
+			}
+			assert.Equal(t, StatusGenerated, pr.Interfaces[0].Status)
+
+			content, err := os.ReadFile(filepath.Join(dir, "service", "trace", "service_trace.go"))
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(content), want)
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_LoaderFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		loader  string
+		wantErr string
+	}{
+		{
+			name:    "ast",
+			loader:  LoaderAST,
+			wantErr: "failed to resolve the methods of interface UserService for filters",
+		},
+		{
+			name:   "types",
+			loader: LoaderTypes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				config.FileName:      "output: trace\nno-generate: true\nfilter:\n  min-methods: 3\npackages:\n  example.com/app/service:\n",
+				"service/service.go": loaderService,
+				"model/model.go":     "package model\n\nimport \"context\"\n\ntype Reader interface {\n\tRead(ctx context.Context, id string) ([]byte, error)\n}\n",
+				"lib/v2/lib.go":      "package lib\n\nimport \"context\"\n\ntype Item struct{}\n\ntype Doer interface {\n\tDo(ctx context.Context, item Item) error\n}\n",
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+
+			cmd := NewGenerateCommand()
+			cmd.loader = tt.loader
+			err = cmd.runWithConfig(cfg, configPath, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.NoFileExists(t, filepath.Join(dir, "service", "trace", "service_trace.go"))
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, cmd.report.Packages, 1)
+			require.Len(t, cmd.report.Packages[0].Interfaces, 1)
+			assert.Equal(t, StatusGenerated, cmd.report.Packages[0].Interfaces[0].Status)
+		})
+	}
+}
+
+func TestGenerateCommand_Run_UnknownLoader(t *testing.T) {
+	err := NewGenerateCommand().Run([]string{"--loader", "ssa"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown loader "ssa"`)
+}
+
+func TestListPackages_Loader(t *testing.T) {
+	tests := []struct {
+		name       string
+		loader     string
+		wantStatus string
+		wantReason string
+	}{
+		{
+			name:       "ast",
+			loader:     LoaderAST,
+			wantStatus: StatusSkipped,
+			wantReason: "failed to parse interface declaration",
+		},
+		{
+			name:       "types",
+			loader:     LoaderTypes,
+			wantStatus: StatusGenerated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				config.FileName:      "output: trace\nloader: " + tt.loader + "\npackages:\n  example.com/app/service:\n",
+				"service/service.go": loaderService,
+				"model/model.go":     "package model\n\nimport \"context\"\n\ntype Reader interface {\n\tRead(ctx context.Context, id string) ([]byte, error)\n}\n",
+				"lib/v2/lib.go":      "package lib\n\nimport \"context\"\n\ntype Item struct{}\n\ntype Doer interface {\n\tDo(ctx context.Context, item Item) error\n}\n",
+			})
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			cfg, err := config.Load(configPath)
+			require.NoError(t, err)
+
+			listed, err := listPackages(cfg, configPath)
+			require.NoError(t, err)
+
+			require.Len(t, listed, 1)
+			require.Len(t, listed[0].Files, 1)
+			require.Len(t, listed[0].Files[0].Interfaces, 1)
+			li := listed[0].Files[0].Interfaces[0]
+			assert.Equal(t, tt.wantStatus, li.Status)
+			assert.Contains(t, li.Reason, tt.wantReason)
+			if tt.wantStatus == StatusGenerated {
+				assert.Len(t, li.Methods, 3)
+			}
+		})
+	}
+}
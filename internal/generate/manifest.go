@@ -0,0 +1,262 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
+)
+
+// ManifestFile is written to every output directory in config mode. It records
+// the hashes of the inputs each generated file was generated from, so unchanged
+// packages are skipped on the next run regardless of file modification times.
+const ManifestFile = ".ddtrace.sum"
+
+// Kinds of manifest entries.
+const (
+	// entryTool is the ddtrace version and the hash of its templates.
+	entryTool = "tool"
+
+	// entryConfig is the hash of the package's resolved config section.
+	entryConfig = "config"
+
+	// entrySource is the hash of a Go file of the source package.
+	entrySource = "source"
+
+	// entryDep is the hash of the Go files, or the module version, of another
+	// package an interface's method set was resolved from.
+	entryDep = "dep"
+)
+
+// manifestEntry is an input of a generated file: one line of the manifest.
+type manifestEntry struct {
+	Kind string
+	Name string
+	Hash string
+}
+
+// manifest maps generated file names to the inputs they were generated from.
+type manifest map[string][]manifestEntry
+
+// readManifest reads the manifest at path.
+func readManifest(path string) (manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := manifest{}
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, errors.Errorf("%s:%d: malformed manifest entry", path, line)
+		}
+		m[fields[0]] = append(m[fields[0]], manifestEntry{Kind: fields[1], Name: fields[2], Hash: fields[3]})
+	}
+	return m, sc.Err()
+}
+
+// encode returns the manifest sorted by generated file name, one entry per line.
+func (m manifest) encode() []byte {
+	outputs := make([]string, 0, len(m))
+	for output := range m {
+		outputs = append(outputs, output)
+	}
+	sort.Strings(outputs)
+
+	var buf bytes.Buffer
+	for _, output := range outputs {
+		for _, e := range m[output] {
+			fmt.Fprintf(&buf, "%s %s %s %s\n", output, e.Kind, e.Name, e.Hash)
+		}
+	}
+	return buf.Bytes()
+}
+
+// templatesHash is the hash of every template the generated code is rendered from.
+var templatesHash = func() string {
+	kinds := make([]string, 0, len(decoratorTemplates))
+	for kind := range decoratorTemplates {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	texts := []string{minimalHeaderTemplate, registryTemplate}
+	for _, kind := range kinds {
+		texts = append(texts, decoratorTemplates[kind])
+	}
+	for _, adapter := range []string{AdapterFx, AdapterWire} {
+		texts = append(texts, adapterTemplates[adapter])
+	}
+	return hashBytes([]byte(strings.Join(texts, "\x00")))
+}()
+
+// packageInputs returns the manifest entries shared by every file generated for
+// a package: the tool, its config section and every Go file of srcDir.
+func packageInputs(cfg *config.Config, pkgCfg config.PackageConfig, srcDir string) ([]manifestEntry, error) {
+	section, err := json.Marshal(struct {
+		NoGenerate bool
+		Loader     string   `json:",omitempty"`
+		Tags       []string `json:",omitempty"`
+		Platform   string
+		Package    config.PackageConfig
+	}{cfg.NoGenerate, cfg.Loader, cfg.Tags, scanner.Platform(), pkgCfg})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash config")
+	}
+
+	entries := []manifestEntry{
+		{Kind: entryTool, Name: cli.Version(), Hash: templatesHash},
+		{Kind: entryConfig, Name: config.FileName, Hash: hashBytes(section)},
+	}
+
+	files, err := goFiles(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		hash, err := hashFiles(srcDir, []string{name})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, manifestEntry{Kind: entrySource, Name: name, Hash: hash})
+	}
+	return entries, nil
+}
+
+// depEntries returns the manifest entries of the packages interfaces were resolved from.
+func depEntries(md *moduleDeps, importPaths []string) ([]manifestEntry, error) {
+	entries := make([]manifestEntry, 0, len(importPaths))
+	for _, importPath := range importPaths {
+		hash, ok, err := md.hash(importPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to hash %s", importPath)
+		}
+		if ok {
+			entries = append(entries, manifestEntry{Kind: entryDep, Name: importPath, Hash: hash})
+		}
+	}
+	return entries, nil
+}
+
+// upToDate reports whether every file recorded in the manifest of outDir exists
+// and was generated from inputs with the current hashes.
+func upToDate(cfg *config.Config, rp config.ResolvedPackage, srcDir, outDir string, md *moduleDeps) bool {
+	m, err := readManifest(filepath.Join(outDir, ManifestFile))
+	if err != nil || len(m) == 0 {
+		return false
+	}
+
+	inputs, err := packageInputs(cfg, rp.Config, srcDir)
+	if err != nil {
+		return false
+	}
+
+	for output, entries := range m {
+		if _, err := os.Stat(filepath.Join(outDir, output)); err != nil {
+			return false
+		}
+
+		i := slices.IndexFunc(entries, func(e manifestEntry) bool { return e.Kind == entryDep })
+		if i < 0 {
+			i = len(entries)
+		}
+		if !slices.Equal(entries[:i], inputs) {
+			return false
+		}
+
+		for _, e := range entries[i:] {
+			if hash, ok, err := md.hash(e.Name); err != nil || !ok || hash != e.Hash {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// writeManifest records the inputs of the files generated for a package.
+// deps maps generated file names to the packages their interfaces were resolved from;
+// files missing from deps, like the registry, depend on all of them.
+func (gc *GenerateCommand) writeManifest(cfg *config.Config, pkgCfg config.PackageConfig, srcDir, outDir string, md *moduleDeps, files []FileReport, deps map[string][]string) error {
+	inputs, err := packageInputs(cfg, pkgCfg, srcDir)
+	if err != nil {
+		return err
+	}
+
+	var all []string
+	for _, paths := range deps {
+		all = append(all, paths...)
+	}
+	slices.Sort(all)
+	all = slices.Compact(all)
+
+	m := manifest{}
+	for _, f := range files {
+		if f.Status == StatusRemoved {
+			continue
+		}
+		output := filepath.Base(f.Path)
+
+		paths, ok := deps[output]
+		if !ok {
+			paths = all
+		}
+		depInputs, err := depEntries(md, paths)
+		if err != nil {
+			return err
+		}
+		m[output] = append(slices.Clone(inputs), depInputs...)
+	}
+
+	return gc.fs.WriteFile(filepath.Join(outDir, ManifestFile), m.encode(), 0664)
+}
+
+// goFiles returns the sorted names of the non-test Go files in dir.
+func goFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, TestSuffix) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// hashFiles returns the go.sum style hash of the named files of dir.
+func hashFiles(dir string, names []string) (string, error) {
+	return dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, name))
+	})
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "h1:" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,300 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+// writeModule writes files (relative path -> content) into a temporary
+// example.com/app module and returns its directory.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files["go.mod"] = "module example.com/app\n\ngo 1.23\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+// chdir makes dir the working directory for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) }) //nolint: errcheck
+}
+
+const manifestService = `package service
+
+import (
+	"context"
+
+	"example.com/app/model"
+	"example.com/app/other"
+	"example.com/shared"
+)
+
+type UserService interface {
+	model.Reader
+	shared.Pinger
+
+	GetUser(ctx context.Context, id other.ID) error
+}
+`
+
+func TestRunWithConfig_Manifest(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":             "module example.com/app\n\ngo 1.23\n\nrequire example.com/shared v0.0.0\n\nreplace example.com/shared => ./shared\n",
+		config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+		"service/service.go": manifestService,
+		"model/model.go":     "package model\n\nimport (\n\t\"context\"\n\n\t\"example.com/app/base\"\n)\n\ntype Reader interface {\n\tbase.Closer\n\tRead(ctx context.Context) error\n}\n",
+		"base/base.go":       "package base\n\nimport \"context\"\n\ntype Closer interface {\n\tClose(ctx context.Context) error\n}\n",
+		"other/other.go":     "package other\n\ntype ID string\n",
+		"shared/go.mod":      "module example.com/shared\n\ngo 1.23\n",
+		"shared/shared.go":   "package shared\n\nimport \"context\"\n\ntype Pinger interface {\n\tPing(ctx context.Context) error\n}\n",
+	})
+	chdir(t, dir)
+	configPath := filepath.Join(dir, config.FileName)
+
+	run := func(t *testing.T) string {
+		t.Helper()
+
+		cfg, err := config.Load(configPath)
+		require.NoError(t, err)
+
+		cmd := NewGenerateCommand()
+		require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+		require.Len(t, cmd.report.Packages, 1)
+		return cmd.report.Packages[0].Status
+	}
+	write := func(t *testing.T, name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0o644))
+	}
+	appendTo := func(t *testing.T, name, content string) {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		write(t, name, string(data)+content)
+	}
+
+	require.Equal(t, StatusProcessed, run(t))
+
+	sum, err := os.ReadFile(filepath.Join(dir, "service", "trace", ManifestFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(sum), "service_trace.go tool dev h1:")
+	assert.Contains(t, string(sum), "service_trace.go config "+config.FileName+" h1:")
+	assert.Contains(t, string(sum), "service_trace.go source service.go h1:")
+	assert.Contains(t, string(sum), "service_trace.go dep example.com/app/model h1:")
+	assert.Contains(t, string(sum), "service_trace.go dep example.com/app/base h1:")
+	assert.Contains(t, string(sum), "service_trace.go dep example.com/shared h1:")
+	assert.NotContains(t, string(sum), "example.com/app/other")
+
+	steps := []struct {
+		name   string
+		change func(t *testing.T)
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			change: func(t *testing.T) {},
+			want:   StatusSkipped,
+		},
+		{
+			name: "touched source",
+			change: func(t *testing.T) {
+				future := time.Now().Add(time.Hour)
+				require.NoError(t, os.Chtimes(filepath.Join(dir, "service", "service.go"), future, future))
+			},
+			want: StatusSkipped,
+		},
+		{
+			name: "changed source",
+			change: func(t *testing.T) {
+				write(t, "service/service.go", strings.Replace(manifestService, "id other.ID", "userID other.ID", 1))
+			},
+			want: StatusProcessed,
+		},
+		{
+			name:   "changed package not embedded",
+			change: func(t *testing.T) { appendTo(t, "other/other.go", "\ntype Name string\n") },
+			want:   StatusSkipped,
+		},
+		{
+			name:   "changed embedded interface",
+			change: func(t *testing.T) { appendTo(t, "model/model.go", "\ntype Writer interface{}\n") },
+			want:   StatusProcessed,
+		},
+		{
+			name:   "changed transitively embedded interface",
+			change: func(t *testing.T) { appendTo(t, "base/base.go", "\ntype Opener interface{}\n") },
+			want:   StatusProcessed,
+		},
+		{
+			name:   "changed interface of a replaced module",
+			change: func(t *testing.T) { appendTo(t, "shared/shared.go", "\ntype Checker interface{}\n") },
+			want:   StatusProcessed,
+		},
+		{
+			name:   "new source file",
+			change: func(t *testing.T) { write(t, "service/extra.go", "package service\n") },
+			want:   StatusProcessed,
+		},
+		{
+			name: "changed config",
+			change: func(t *testing.T) {
+				write(t, config.FileName, "output: trace\nno-generate: true\npointer-receivers: true\npackages:\n  example.com/app/service:\n")
+			},
+			want: StatusProcessed,
+		},
+		{
+			name: "removed output",
+			change: func(t *testing.T) {
+				require.NoError(t, os.Remove(filepath.Join(dir, "service", "trace", "service_trace.go")))
+			},
+			want: StatusProcessed,
+		},
+	}
+
+	for _, step := range steps {
+		step.change(t)
+		assert.Equal(t, step.want, run(t), step.name)
+		assert.Equal(t, StatusSkipped, run(t), step.name+", second run")
+	}
+}
+
+func TestRunWithConfig_ManifestSkippedDeps(t *testing.T) {
+	tests := []struct {
+		name   string
+		common map[string]string
+	}{
+		{
+			name:   "interface missing",
+			common: map[string]string{"common/common.go": "package common\n"},
+		},
+		{
+			name: "package missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{
+				config.FileName: "output: trace\nno-generate: true\npackages:\n  example.com/app/svc2:\n",
+				"svc2/svc2.go":  "package svc2\n\nimport (\n\t\"context\"\n\n\t\"example.com/app/common\"\n)\n\ntype A interface {\n\tcommon.Pinger\n}\n\ntype B interface {\n\tGet(ctx context.Context) error\n}\n",
+			}
+			for name, content := range tt.common {
+				files[name] = content
+			}
+			dir := writeModule(t, files)
+			chdir(t, dir)
+			configPath := filepath.Join(dir, config.FileName)
+
+			run := func(t *testing.T) *PackageReport {
+				t.Helper()
+
+				cfg, err := config.Load(configPath)
+				require.NoError(t, err)
+
+				cmd := NewGenerateCommand()
+				require.NoError(t, cmd.runWithConfig(cfg, configPath, nil))
+				require.Len(t, cmd.report.Packages, 1)
+				return cmd.report.Packages[0]
+			}
+			status := func(pr *PackageReport, name string) string {
+				for _, ir := range pr.Interfaces {
+					if ir.Name == name {
+						return ir.Status
+					}
+				}
+				return ""
+			}
+
+			pr := run(t)
+			require.Equal(t, StatusProcessed, pr.Status)
+			assert.Equal(t, StatusSkipped, status(pr, "A"))
+			assert.Equal(t, StatusGenerated, status(pr, "B"))
+			assert.Equal(t, StatusSkipped, run(t).Status)
+
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "common"), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "common.go"), []byte("package common\n\nimport \"context\"\n\ntype Pinger interface {\n\tPing(ctx context.Context) error\n}\n"), 0o644))
+
+			pr = run(t)
+			require.Equal(t, StatusProcessed, pr.Status)
+			assert.Equal(t, StatusGenerated, status(pr, "A"))
+			assert.Equal(t, StatusSkipped, run(t).Status)
+		})
+	}
+}
+
+func TestModuleDeps_Hash(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.23
+
+require (
+	example.com/lib v1.2.0
+	example.com/fork v1.0.0
+	example.com/shared v0.0.0
+)
+
+replace example.com/fork => example.com/fork2 v1.0.1
+
+replace example.com/shared => ./shared
+`,
+		"model/model.go":         "package model\n",
+		"shared/go.mod":          "module example.com/shared\n",
+		"shared/sub/sub.go":      "package sub\n",
+		"shared/sub/sub_test.go": "package sub\n",
+	})
+
+	md, err := loadModuleDeps(dir)
+	require.NoError(t, err)
+
+	modelHash, err := hashFiles(filepath.Join(dir, "model"), []string{"model.go"})
+	require.NoError(t, err)
+	subHash, err := hashFiles(filepath.Join(dir, "shared", "sub"), []string{"sub.go"})
+	require.NoError(t, err)
+	emptyHash, err := hashFiles(filepath.Join(dir, "missing"), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		importPath string
+		want       string
+		wantOK     bool
+	}{
+		{importPath: "example.com/app/model", want: modelHash, wantOK: true},
+		{importPath: "example.com/shared/sub", want: subHash, wantOK: true},
+		{importPath: "example.com/app/missing", want: emptyHash, wantOK: true},
+		{importPath: "example.com/lib/client", want: "example.com/lib@v1.2.0", wantOK: true},
+		{importPath: "example.com/fork", want: "example.com/fork2@v1.0.1", wantOK: true},
+		{importPath: "example.com/library", wantOK: false},
+		{importPath: "context", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			hash, ok, err := md.hash(tt.importPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, hash)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package generate
+
+import (
+	"bytes"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+const (
+	// generatedHeader starts every file generated by ddtrace.
+	generatedHeader = "// Code generated by ddtrace. DO NOT EDIT."
+
+	// RegistryFile is the file holding the Wrap<Interface> functions and All of an output package.
+	RegistryFile = "ddtrace_registry" + TraceSuffix
+
+	// AdapterFx generates an fx.Decorate option (go.uber.org/fx) next to the registry.
+	AdapterFx = "fx"
+
+	// AdapterWire generates a provider set (github.com/google/wire) next to the registry.
+	AdapterWire = "wire"
+)
+
+var adapterTemplates = map[string]string{
+	AdapterFx:   fxAdapterTemplate,
+	AdapterWire: wireAdapterTemplate,
+}
+
+// registryInterface describes an interface whose decorators were generated.
+type registryInterface struct {
+	Name    string
+	Tracing string
+	Chain   bool
+	Logging bool
+
+	// Deps are the other packages its method set was resolved from.
+	Deps []string
+}
+
+type registryData struct {
+	Package     string
+	SourceAlias string
+	SourcePath  string
+	SamePackage bool
+	Interfaces  []registryInterface
+}
+
+// Type returns the type of the source interface name in the output package.
+func (d registryData) Type(name string) string {
+	if d.SamePackage {
+		return name
+	}
+	return d.SourceAlias + "." + name
+}
+
+// registryEnabled reports whether a registry file is generated for the package.
+func registryEnabled(pkgCfg config.PackageConfig) bool {
+	return (pkgCfg.Registry != nil && *pkgCfg.Registry) || len(pkgCfg.Adapters) > 0
+}
+
+// adapterFile returns the name of the file generated for an adapter.
+func adapterFile(adapter string) string {
+	return "ddtrace_" + adapter + TraceSuffix
+}
+
+// registryFiles returns the names of the files generated next to the registry.
+func registryFiles() []string {
+	return []string{RegistryFile, adapterFile(AdapterFx), adapterFile(AdapterWire)}
+}
+
+// generateRegistry writes the registry file and the configured adapters of an output package,
+// and removes the ones a previous run generated that are no longer configured.
+func (gc *GenerateCommand) generateRegistry(sourcePackage, dstPackage *packages.Package, outDir string, pkgCfg config.PackageConfig, ifaces []registryInterface, pr *PackageReport) error {
+	for _, adapter := range pkgCfg.Adapters {
+		if _, ok := adapterTemplates[adapter]; !ok {
+			return errors.Errorf("unknown adapter %q", adapter)
+		}
+	}
+
+	var exported []registryInterface
+	for _, iface := range ifaces {
+		if token.IsExported(iface.Name) {
+			exported = append(exported, iface)
+		}
+	}
+
+	files := map[string]string{}
+	if registryEnabled(pkgCfg) && len(exported) > 0 {
+		files[RegistryFile] = registryTemplate
+		for _, adapter := range pkgCfg.Adapters {
+			files[adapterFile(adapter)] = adapterTemplates[adapter]
+		}
+	}
+
+	data := registryData{
+		Package:     dstPackage.Name,
+		SourceAlias: "_source" + upFirst(sourcePackage.Name),
+		SourcePath:  sourcePackage.PkgPath,
+		SamePackage: dstPackage.PkgPath == sourcePackage.PkgPath,
+		Interfaces:  exported,
+	}
+
+	for _, name := range registryFiles() {
+		path := filepath.Join(outDir, name)
+		text, ok := files[name]
+		if !ok {
+			if err := gc.removeGenerated(path, pr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := gc.renderGenerated(path, text, data, pr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeGenerated removes the file at path if ddtrace generated it.
+func (gc *GenerateCommand) removeGenerated(path string, pr *PackageReport) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(content, []byte(generatedHeader)) {
+		return nil
+	}
+
+	if err := gc.fs.Remove(path); err != nil {
+		return err
+	}
+	pr.Files = append(pr.Files, FileReport{Path: path, Status: StatusRemoved})
+	return nil
+}
+
+// renderGenerated executes a registry template and writes the result to path.
+func (gc *GenerateCommand) renderGenerated(path, text string, data registryData, pr *PackageReport) error {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(helperFuncs).Parse(text)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse template for %s", filepath.Base(path))
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return errors.Wrapf(err, "failed to render %s", filepath.Base(path))
+	}
+
+	written, err := gc.writeGenerated(path, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	pr.addFile(path, written)
+	return nil
+}
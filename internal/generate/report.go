@@ -0,0 +1,129 @@
+package generate
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Exit codes of gen --format json. In text mode gen exits with 0 when files changed, so
+// go generate doesn't fail, and with 1 when a package failed.
+const (
+	// ExitNoop means no file was written.
+	ExitNoop = 0
+
+	// ExitErrors means at least one package failed; the report lists every error.
+	ExitErrors = 1
+
+	// ExitChanged means generated files were written or removed and no package failed.
+	ExitChanged = 3
+)
+
+// Statuses of packages and files in a gen report.
+const (
+	// StatusProcessed marks a package that was (re)generated.
+	StatusProcessed = "processed"
+
+	// StatusWritten marks a generated file whose content changed.
+	StatusWritten = "written"
+
+	// StatusUnchanged marks a generated file that already had the generated content.
+	StatusUnchanged = "unchanged"
+
+	// StatusRemoved marks a previously generated file that is no longer configured.
+	StatusRemoved = "removed"
+)
+
+// Report summarizes a gen run.
+type Report struct {
+	Changed  bool             `json:"changed"`
+	Packages []*PackageReport `json:"packages"`
+	Errors   []string         `json:"errors,omitempty"`
+	Cache    *CacheReport     `json:"cache,omitempty"`
+
+	mu sync.Mutex
+}
+
+// CacheReport counts the method sets found and not found in the persistent cache.
+type CacheReport struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// PackageReport is the outcome of generating a single package.
+// Fallback is the reason a package was generated from its AST although the types loader was selected.
+type PackageReport struct {
+	ImportPath string            `json:"package"`
+	Status     string            `json:"status"`
+	Reason     string            `json:"reason,omitempty"`
+	Fallback   string            `json:"fallback,omitempty"`
+	Errors     []string          `json:"errors,omitempty"`
+	Files      []FileReport      `json:"files,omitempty"`
+	Interfaces []InterfaceReport `json:"interfaces,omitempty"`
+}
+
+// FileReport is a generated file and whether it was written.
+type FileReport struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// InterfaceReport is an interface and whether its decorators were generated.
+type InterfaceReport struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// addPackage registers a package report; safe for concurrent use.
+func (r *Report) addPackage(pr *PackageReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Packages = append(r.Packages, pr)
+}
+
+// addFile records a generated file of the package.
+func (pr *PackageReport) addFile(path string, written bool) {
+	status := StatusUnchanged
+	if written {
+		status = StatusWritten
+	}
+	pr.Files = append(pr.Files, FileReport{Path: path, Status: status})
+}
+
+// finish sorts the report, collects package errors and computes whether anything changed.
+func (r *Report) finish() {
+	sort.Slice(r.Packages, func(i, j int) bool { return r.Packages[i].ImportPath < r.Packages[j].ImportPath })
+
+	r.Errors = nil
+	for _, pr := range r.Packages {
+		for _, e := range pr.Errors {
+			r.Errors = append(r.Errors, pr.ImportPath+": "+e)
+		}
+		for _, f := range pr.Files {
+			if f.Status == StatusWritten || f.Status == StatusRemoved {
+				r.Changed = true
+			}
+		}
+	}
+}
+
+// exitCode returns the documented exit code of the report.
+func (r *Report) exitCode() int {
+	switch {
+	case len(r.Errors) > 0:
+		return ExitErrors
+	case r.Changed:
+		return ExitChanged
+	default:
+		return ExitNoop
+	}
+}
+
+func (r *Report) write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
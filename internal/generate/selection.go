@@ -0,0 +1,177 @@
+package generate
+
+import (
+	"go/token"
+	"slices"
+
+	"github.com/pkg/errors"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
+)
+
+// interfaceSelection decides which interfaces of a package decorators are generated for.
+// The gen and list commands share it, so list reports what gen does.
+type interfaceSelection struct {
+	pkgCfg      config.PackageConfig
+	filter      scanner.Filter
+	samePackage bool
+	methods     methodsResolver
+
+	decisions map[string]interfaceDecision
+	errs      map[string]error
+}
+
+// interfaceDecision is the outcome of the selection of an interface.
+type interfaceDecision struct {
+	// Status is StatusGenerated, StatusIgnored or StatusSkipped, and Reason
+	// explains the last two.
+	Status string
+	Reason string
+
+	// Chain is the configured decorator chain of a generated interface.
+	Chain []string
+
+	// Methods is the method set of a generated interface.
+	Methods map[string]codegen.Method
+
+	// Deps are the other packages the method set was resolved from, or failed to be
+	// resolved from for a skipped interface.
+	Deps []string
+}
+
+// newInterfaceSelection returns the selection of the interfaces of a package configured
+// by pkgCfg. samePackage reports whether decorators are generated in the source package,
+// which allows unexported methods.
+func newInterfaceSelection(pkgCfg config.PackageConfig, samePackage bool, methods methodsResolver) (*interfaceSelection, error) {
+	filter, err := newFilter(pkgCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &interfaceSelection{
+		pkgCfg:      pkgCfg,
+		filter:      filter,
+		samePackage: samePackage,
+		methods:     methods,
+		decisions:   map[string]interfaceDecision{},
+		errs:        map[string]error{},
+	}, nil
+}
+
+// decide returns the decision for iface. Filters failing to resolve the method set
+// of iface, invalid method directives and invalid decorator chains are errors. Decisions are cached by name.
+func (s *interfaceSelection) decide(iface scanner.InterfaceInfo) (interfaceDecision, error) {
+	if err, ok := s.errs[iface.Name]; ok {
+		return interfaceDecision{}, err
+	}
+	if d, ok := s.decisions[iface.Name]; ok {
+		return d, nil
+	}
+
+	d, err := s.resolve(iface)
+	if err != nil {
+		s.errs[iface.Name] = err
+		return interfaceDecision{}, err
+	}
+	s.decisions[iface.Name] = d
+	return d, nil
+}
+
+func (s *interfaceSelection) resolve(iface scanner.InterfaceInfo) (interfaceDecision, error) {
+	if iface.Ignored {
+		return interfaceDecision{Status: StatusIgnored, Reason: "//ddtrace:ignore directive"}, nil
+	}
+	if ic, ok := s.pkgCfg.Interfaces[iface.Name]; ok && ic != nil && ic.Ignore {
+		return interfaceDecision{Status: StatusIgnored, Reason: "ignore: true in " + config.FileName}, nil
+	}
+
+	reason, err := filteredOut(s.filter, iface, s.pkgCfg, s.methods)
+	if err != nil {
+		return interfaceDecision{}, err
+	}
+	if reason != "" {
+		return interfaceDecision{Status: StatusIgnored, Reason: "filter: " + reason}, nil
+	}
+
+	chain, err := configuredChain(&s.pkgCfg, iface.Name)
+	if err != nil {
+		return interfaceDecision{}, err
+	}
+
+	methods, deps, err := s.methods(iface.Name)
+	if err != nil {
+		// Invalid directives are errors, like they are on interfaces.
+		var de *codegen.DirectiveError
+		if errors.As(err, &de) {
+			return interfaceDecision{}, err
+		}
+		return interfaceDecision{Status: StatusSkipped, Reason: err.Error(), Deps: deps}, nil
+	}
+
+	if !s.samePackage {
+		for _, name := range sortedMethodNames(methods) {
+			if !token.IsExported(name) {
+				return interfaceDecision{Status: StatusSkipped, Reason: "unexported method " + name, Deps: deps}, nil
+			}
+		}
+	}
+
+	return interfaceDecision{Status: StatusGenerated, Chain: chain, Methods: methods, Deps: deps}, nil
+}
+
+// skippedDeps returns the sorted packages the skipped interfaces depend on: generating
+// them again is needed when one of these changes.
+func (s *interfaceSelection) skippedDeps() []string {
+	var deps []string
+	for _, d := range s.decisions {
+		if d.Status == StatusSkipped {
+			deps = append(deps, d.Deps...)
+		}
+	}
+	slices.Sort(deps)
+	return slices.Compact(deps)
+}
+
+// selectInterfaces drops the interfaces the selection ignores from fileGroups, and the
+// files left without interfaces. Interfaces the selection fails to decide on are kept,
+// so generating their file reports the error.
+func selectInterfaces(fileGroups []scanner.FileInterfaces, sel *interfaceSelection) []scanner.FileInterfaces {
+	var result []scanner.FileInterfaces
+	for _, fg := range fileGroups {
+		var selected []scanner.InterfaceInfo
+		for _, iface := range fg.Interfaces {
+			if d, err := sel.decide(iface); err != nil || d.Status != StatusIgnored {
+				selected = append(selected, iface)
+			}
+		}
+		if len(selected) > 0 {
+			fg.Interfaces = selected
+			result = append(result, fg)
+		}
+	}
+	return result
+}
+
+// methodStatus returns the status of a method of a generated interface, and the reason
+// it's passed through.
+func methodStatus(m codegen.Method) (string, string) {
+	switch {
+	case m.Ignored:
+		return StatusPassThrough, "//ddtrace:ignore directive"
+	case !m.AcceptsContext:
+		return StatusPassThrough, "first parameter is not a context.Context"
+	default:
+		return StatusTraced, ""
+	}
+}
+
+func sortedMethodNames(methods map[string]codegen.Method) []string {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
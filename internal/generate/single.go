@@ -5,12 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"text/template"
 
 	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
 
 	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
 	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
 )
 
@@ -20,7 +20,9 @@ func (gc *GenerateCommand) runSinglePackage() error {
 		gc.outputDir = "./trace"
 	}
 
-	sourcePackage, err := scanner.Load(gc.sourcePkg)
+	tags := splitTags(gc.tags)
+
+	sourcePackage, err := scanner.Load(gc.sourcePkg, tags)
 	if err != nil {
 		return errors.Wrap(err, "failed to load source package")
 	}
@@ -51,38 +53,64 @@ func (gc *GenerateCommand) runSinglePackage() error {
 	}
 
 	outPkgName := filepath.Base(outDir)
-	dstPackage, err := scanner.Load(outDir)
+	dstPackage, err := scanner.Load(outDir, tags)
 	if err != nil {
 		dstPackage = &packages.Package{Name: outPkgName}
 	}
 
-	headerTmpl, err := template.New("header").Funcs(helperFuncs).Parse(minimalHeaderTemplate)
-	if err != nil {
-		return errors.Wrap(err, "failed to parse header template")
-	}
-	bodyTmpl, err := template.New("body").Funcs(helperFuncs).Parse(datadogTemplate)
+	headerTmpl, bodyTmpls, err := parseTemplates()
 	if err != nil {
-		return errors.Wrap(err, "failed to parse body template")
+		return err
 	}
 
 	sharedFS := token.NewFileSet()
-	pkgCache := codegen.NewPackageCache()
+	pkgCache := codegen.NewPackageCache(tags)
 
+	pr := &PackageReport{ImportPath: sourcePackage.PkgPath, Status: StatusProcessed}
+	gc.report.addPackage(pr)
+
+	var typedPackage *packages.Package
+	if gc.loader == LoaderTypes {
+		typed, fallbacks, err := loadTyped(sharedFS, []*packages.Package{sourcePackage}, tags)
+		if err != nil {
+			return err
+		}
+		typedPackage, pr.Fallback = typed[sourcePackage.PkgPath], fallbacks[sourcePackage.PkgPath]
+	}
+
+	// The single-package flow has no config: all the interfaces without an ignore directive are selected.
+	sel, err := newInterfaceSelection(config.PackageConfig{}, dstPackage.PkgPath == sourcePackage.PkgPath, interfaceMethods(sharedFS, sourcePackage, astPkg, typedPackage, pkgCache))
+	if err != nil {
+		return err
+	}
+
+	var errs errorList
 	wroteGoGenerate := false
 	for _, fg := range fileGroups {
 		outFileName := strings.TrimSuffix(fg.FileName, ".go") + TraceSuffix
 		outFilePath := filepath.Join(outDir, outFileName)
 
-		includeGoGenerate := !gc.noGenerate && !wroteGoGenerate
+		var goGenerate *generateDirective
+		if !gc.noGenerate && !wroteGoGenerate {
+			goGenerate = &generateDirective{tags: tags, loader: gc.loader}
+		}
 
-		if err := gc.generateFileDecorators(sourcePackage, astPkg, dstPackage, headerTmpl, bodyTmpl, sharedFS, pkgCache, fg, outFilePath, includeGoGenerate, nil); err != nil {
-			return errors.Wrapf(err, "failed to generate for %s", fg.FileName)
+		generated, err := gc.generateFileDecorators(sourcePackage, astPkg, typedPackage, dstPackage, headerTmpl, bodyTmpls, sharedFS, pkgCache, fg, outFilePath, goGenerate, nil, sel, pr)
+		if err != nil {
+			for _, e := range flatten(err) {
+				e = errors.Wrapf(e, "failed to generate for %s", fg.FileName)
+				pr.Status, pr.Errors = StatusError, append(pr.Errors, e.Error())
+				errs = append(errs, e)
+			}
+			if gc.failFast {
+				break
+			}
 		}
 
-		if includeGoGenerate {
+		if goGenerate != nil && len(generated) > 0 {
 			wroteGoGenerate = true
 		}
 	}
 
-	return nil
+	return errs.err()
 }
@@ -1,12 +1,15 @@
 package generate
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/Masterminds/sprig/v3"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -29,9 +32,11 @@ const datadogTemplate = `import (
     "context"
 
     "github.com/tuanvm-tyson/ddtrace/tracing"
+    "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 {{ $decorator := (or .Vars.DecoratorName (printf "%sWithTracing" .Interface.Name)) }}
+{{ $ptr := (ternary "*" "" .Vars.PointerReceivers) }}
 {{ $spanNameType := (or .Vars.SpanNamePrefix .Interface.Name) }}
 
 // {{$decorator}} implements {{.Interface.Name}} interface instrumented with Datadog tracing
@@ -40,19 +45,28 @@ type {{$decorator}} struct {
   _cfg tracing.TracingConfig
 }
 
+var _ {{.Interface.Type}} = (*{{$decorator}})(nil)
+
 // New{{$decorator}} returns {{$decorator}}
-func New{{$decorator}} (base {{.Interface.Type}}, opts ...tracing.TracingOption) {{$decorator}} {
-  return {{$decorator}} {
+func New{{$decorator}} (base {{.Interface.Type}}, opts ...tracing.TracingOption) {{$ptr}}{{$decorator}} {
+  return {{ternary "&" "" .Vars.PointerReceivers}}{{$decorator}} {
     {{.Interface.Name}}: base,
-    _cfg: tracing.NewTracingConfig(opts...),
+    _cfg: tracing.NewTracingConfig({{if .Vars.Metrics}}append([]tracing.TracingOption{tracing.WithMetrics()}, opts...)...{{else}}opts...{{end}}),
   }
 }
 
+{{if not (index .Interface.Methods "Unwrap").Name}}
+// Unwrap returns the {{.Interface.Name}} wrapped by {{$decorator}}
+func (_d {{$ptr}}{{$decorator}}) Unwrap() any {
+  return _d.{{.Interface.Name}}
+}
+{{end}}
+
 {{range $method := .Interface.Methods}}
-  {{if $method.AcceptsContext}}
+  {{if $method.Traced}}
     // {{$method.Name}} implements {{$.Interface.Name}}
-func (_d {{$decorator}}) {{$method.Declaration}} {
-  span, ctx := _d._cfg.StartSpan(ctx, "{{$spanNameType}}.{{$method.Name}}")
+func (_d {{$ptr}}{{$decorator}}) {{$method.Declaration}} {
+  span, ctx := _d._cfg.StartSpan(ctx, "{{$method.OperationName $spanNameType}}"{{$method.SpanOptions $.Vars.Span}})
   defer func() {
     _d._cfg.FinishSpan(span, {{if $method.ReturnsError}}err{{else}}nil{{end}}, {{$method.ParamsMap}}, {{$method.ResultsMap}})
   }()
@@ -62,6 +76,366 @@ func (_d {{$decorator}}) {{$method.Declaration}} {
 {{end}}
 `
 
+const loggingTemplate = `import (
+    "context"
+    "log/slog"
+
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+{{ $decorator := (printf "%sWithLogging" .Interface.Name) }}
+{{ $ptr := (ternary "*" "" .Vars.PointerReceivers) }}
+{{ $spanNameType := (or .Vars.SpanNamePrefix .Interface.Name) }}
+
+// {{$decorator}} implements {{.Interface.Name}} interface instrumented with structured logging
+type {{$decorator}} struct {
+  {{.Interface.Type}}
+  _log tracing.LoggingConfig
+}
+
+var _ {{.Interface.Type}} = (*{{$decorator}})(nil)
+
+// New{{$decorator}} returns {{$decorator}}
+func New{{$decorator}} (base {{.Interface.Type}}, opts ...tracing.LoggingOption) {{$ptr}}{{$decorator}} {
+  return {{ternary "&" "" .Vars.PointerReceivers}}{{$decorator}} {
+    {{.Interface.Name}}: base,
+    _log: tracing.NewLoggingConfig(opts...),
+  }
+}
+
+{{if not (index .Interface.Methods "Unwrap").Name}}
+// Unwrap returns the {{.Interface.Name}} wrapped by {{$decorator}}
+func (_d {{$ptr}}{{$decorator}}) Unwrap() any {
+  return _d.{{.Interface.Name}}
+}
+{{end}}
+
+{{range $method := .Interface.Methods}}
+  {{if $method.Traced}}
+    // {{$method.Name}} implements {{$.Interface.Name}}
+func (_d {{$ptr}}{{$decorator}}) {{$method.Declaration}} {
+  _attrs := []slog.Attr{ {{- range $param := $method.Params}}{{if has $param.Name $.Vars.LogArgs}}slog.Any("{{$param.Name}}", {{$param.Name}}),{{end}}{{end -}} }
+  _start := _d._log.LogStart(ctx, "{{$method.OperationName $spanNameType}}", _attrs...)
+  defer func() {
+    _d._log.LogFinish(ctx, "{{$method.OperationName $spanNameType}}", _start, {{if $method.ReturnsError}}err{{else}}nil{{end}}, _attrs...)
+  }()
+  {{$method.Pass (printf "_d.%s." $.Interface.Name) }}
+}
+  {{end}}
+{{end}}
+`
+
+const timeoutTemplate = `import (
+    "context"
+    "time"
+
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+{{ $decorator := (printf "%sWithTimeout" .Interface.Name) }}
+{{ $ptr := (ternary "*" "" .Vars.PointerReceivers) }}
+{{ $spanNameType := (or .Vars.SpanNamePrefix .Interface.Name) }}
+
+// {{$decorator}} implements {{.Interface.Name}} interface with per-method deadlines
+type {{$decorator}} struct {
+  {{.Interface.Type}}
+  _timeout tracing.TimeoutConfig
+}
+
+var _ {{.Interface.Type}} = (*{{$decorator}})(nil)
+
+// New{{$decorator}} returns {{$decorator}}
+func New{{$decorator}} (base {{.Interface.Type}}, opts ...tracing.TimeoutOption) {{$ptr}}{{$decorator}} {
+  return {{ternary "&" "" .Vars.PointerReceivers}}{{$decorator}} {
+    {{.Interface.Name}}: base,
+    _timeout: tracing.NewTimeoutConfig(opts...),
+  }
+}
+
+{{if not (index .Interface.Methods "Unwrap").Name}}
+// Unwrap returns the {{.Interface.Name}} wrapped by {{$decorator}}
+func (_d {{$ptr}}{{$decorator}}) Unwrap() any {
+  return _d.{{.Interface.Name}}
+}
+{{end}}
+
+{{range $method := .Interface.Methods}}
+  {{ $timeout := (or (index $.Vars.MethodTimeouts $method.Name) $method.Timeout $.Vars.Timeout) }}
+  {{if and $method.Traced $timeout}}
+    // {{$method.Name}} implements {{$.Interface.Name}}
+func (_d {{$ptr}}{{$decorator}}) {{$method.Declaration}} {
+  ctx, _cancel := _d._timeout.WithTimeout(ctx, "{{$method.OperationName $spanNameType}}", {{duration $timeout}})
+  defer _cancel()
+  {{$method.Pass (printf "_d.%s." $.Interface.Name) }}
+}
+  {{end}}
+{{end}}
+`
+
+const retryTemplate = `import (
+    "context"
+
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+{{ $decorator := (printf "%sWithRetry" .Interface.Name) }}
+{{ $ptr := (ternary "*" "" .Vars.PointerReceivers) }}
+
+// {{$decorator}} implements {{.Interface.Name}} interface retrying failed calls
+type {{$decorator}} struct {
+  {{.Interface.Type}}
+  _retry tracing.RetryConfig
+}
+
+var _ {{.Interface.Type}} = (*{{$decorator}})(nil)
+
+// New{{$decorator}} returns {{$decorator}}
+func New{{$decorator}} (base {{.Interface.Type}}, opts ...tracing.RetryOption) {{$ptr}}{{$decorator}} {
+  return {{ternary "&" "" .Vars.PointerReceivers}}{{$decorator}} {
+    {{.Interface.Name}}: base,
+    _retry: tracing.NewRetryConfig(opts...),
+  }
+}
+
+{{if not (index .Interface.Methods "Unwrap").Name}}
+// Unwrap returns the {{.Interface.Name}} wrapped by {{$decorator}}
+func (_d {{$ptr}}{{$decorator}}) Unwrap() any {
+  return _d.{{.Interface.Name}}
+}
+{{end}}
+
+{{range $method := .Interface.Methods}}
+  {{if and $method.Traced $method.ReturnsError}}
+    // {{$method.Name}} implements {{$.Interface.Name}}
+func (_d {{$ptr}}{{$decorator}}) {{$method.Declaration}} {
+  err = _d._retry.Do(ctx, func(ctx context.Context) error {
+    {{$method.ResultsNames}} = _d.{{$.Interface.Name}}.{{$method.Call}}
+    return err
+  })
+  return
+}
+  {{end}}
+{{end}}
+`
+
+const circuitBreakerTemplate = `import (
+    "context"
+
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+{{ $decorator := (printf "%sWithCircuitBreaker" .Interface.Name) }}
+{{ $ptr := (ternary "*" "" .Vars.PointerReceivers) }}
+
+// {{$decorator}} implements {{.Interface.Name}} interface protected by a circuit breaker
+type {{$decorator}} struct {
+  {{.Interface.Type}}
+  _cb *tracing.CircuitBreaker
+}
+
+var _ {{.Interface.Type}} = (*{{$decorator}})(nil)
+
+// New{{$decorator}} returns {{$decorator}}
+func New{{$decorator}} (base {{.Interface.Type}}, opts ...tracing.CircuitBreakerOption) {{$ptr}}{{$decorator}} {
+  return {{ternary "&" "" .Vars.PointerReceivers}}{{$decorator}} {
+    {{.Interface.Name}}: base,
+    _cb: tracing.NewCircuitBreaker(opts...),
+  }
+}
+
+{{if not (index .Interface.Methods "Unwrap").Name}}
+// Unwrap returns the {{.Interface.Name}} wrapped by {{$decorator}}
+func (_d {{$ptr}}{{$decorator}}) Unwrap() any {
+  return _d.{{.Interface.Name}}
+}
+{{end}}
+
+{{range $method := .Interface.Methods}}
+  {{if and $method.Traced $method.ReturnsError}}
+    // {{$method.Name}} implements {{$.Interface.Name}}
+func (_d {{$ptr}}{{$decorator}}) {{$method.Declaration}} {
+  if err = _d._cb.Allow(); err != nil {
+    return
+  }
+  defer func() {
+    _d._cb.Done(err)
+  }()
+  {{$method.Pass (printf "_d.%s." $.Interface.Name) }}
+}
+  {{end}}
+{{end}}
+`
+
+const chainTemplate = `import (
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+// New{{.Interface.Name}}Chain wraps base with the decorators configured for {{.Interface.Name}},
+// outermost first: {{join ", " .Vars.Decorators}}
+func New{{.Interface.Name}}Chain(base {{.Interface.Type}}, opts ...tracing.ChainOption) {{.Interface.Type}} {
+  _c := tracing.NewChainConfig(opts...)
+  _d := base
+  {{- range $kind := reverse .Vars.Decorators}}
+  {{- if eq $kind "tracing"}}
+  _d = New{{or $.Vars.DecoratorName (printf "%sWithTracing" $.Interface.Name)}}(_d, _c.Tracing...)
+  {{- else if eq $kind "logging"}}
+  _d = New{{$.Interface.Name}}WithLogging(_d, _c.Logging...)
+  {{- else if eq $kind "timeout"}}
+  _d = New{{$.Interface.Name}}WithTimeout(_d, _c.Timeout...)
+  {{- else if eq $kind "retry"}}
+  _d = New{{$.Interface.Name}}WithRetry(_d, _c.Retry...)
+  {{- else if eq $kind "circuit-breaker"}}
+  _d = New{{$.Interface.Name}}WithCircuitBreaker(_d, _c.CircuitBreaker...)
+  {{- end}}
+  {{- end}}
+  return _d
+}
+`
+
+// registryTemplate renders the Wrap<Interface> functions and All of an output package.
+const registryTemplate = `// Code generated by ddtrace. DO NOT EDIT.
+// ddtrace: http://github.com/tuanvm-tyson/ddtrace
+
+package {{.Package}}
+
+import (
+    {{- if not .SamePackage}}
+    {{.SourceAlias}} "{{.SourcePath}}"
+    {{- end}}
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+{{range $iface := .Interfaces}}
+// Wrap{{$iface.Name}} wraps base with every decorator generated for {{$iface.Name}}.
+func Wrap{{$iface.Name}}(base {{$.Type $iface.Name}}, opts ...tracing.ChainOption) {{$.Type $iface.Name}} {
+  {{- if $iface.Chain}}
+  return New{{$iface.Name}}Chain(base, opts...)
+  {{- else}}
+  _c := tracing.NewChainConfig(opts...)
+  var _d {{$.Type $iface.Name}} = base
+  {{- if $iface.Logging}}
+  _d = New{{$iface.Name}}WithLogging(_d, _c.Logging...)
+  {{- end}}
+  return New{{$iface.Tracing}}(_d, _c.Tracing...)
+  {{- end}}
+}
+{{end}}
+
+// All returns a decorator function of the form func({{.Type "X"}}) {{.Type "X"}} for every
+// interface of {{.SourcePath}}, as accepted by dependency injection containers.
+func All(opts ...tracing.ChainOption) []any {
+  return []any{
+    {{- range $iface := .Interfaces}}
+    func(base {{$.Type $iface.Name}}) {{$.Type $iface.Name}} { return Wrap{{$iface.Name}}(base, opts...) },
+    {{- end}}
+  }
+}
+`
+
+// fxAdapterTemplate renders the go.uber.org/fx adapter of a registry.
+const fxAdapterTemplate = `// Code generated by ddtrace. DO NOT EDIT.
+// ddtrace: http://github.com/tuanvm-tyson/ddtrace
+
+package {{.Package}}
+
+import (
+    "go.uber.org/fx"
+
+    "github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+// FxDecorate returns an fx.Decorate option wrapping every interface of {{.SourcePath}}
+// with its generated decorators. Each interface must be provided to the application.
+func FxDecorate(opts ...tracing.ChainOption) fx.Option {
+  return fx.Decorate(All(opts...)...)
+}
+`
+
+// wireAdapterTemplate renders the github.com/google/wire adapter of a registry.
+const wireAdapterTemplate = `// Code generated by ddtrace. DO NOT EDIT.
+// ddtrace: http://github.com/tuanvm-tyson/ddtrace
+
+package {{.Package}}
+
+import (
+    "github.com/google/wire"
+    {{- if not .SamePackage}}
+
+    {{.SourceAlias}} "{{.SourcePath}}"
+    {{- end}}
+)
+
+// ProviderSet provides every interface of {{.SourcePath}} wrapped with its generated decorators.
+// Bind implementations to the <Interface>Base types, e.g.
+// wire.Bind(new({{.Package}}.{{(index .Interfaces 0).Name}}Base), new(*Impl)).
+var ProviderSet = wire.NewSet(
+  {{- range $iface := .Interfaces}}
+  Provide{{$iface.Name}},
+  {{- end}}
+)
+{{range $iface := .Interfaces}}
+// {{$iface.Name}}Base is the undecorated {{$iface.Name}} consumed by Provide{{$iface.Name}}.
+type {{$iface.Name}}Base interface {
+  {{$.Type $iface.Name}}
+}
+
+// Provide{{$iface.Name}} wraps base with every decorator generated for {{$iface.Name}}.
+func Provide{{$iface.Name}}(base {{$iface.Name}}Base) {{$.Type $iface.Name}} {
+  return Wrap{{$iface.Name}}(base)
+}
+{{end}}
+`
+
+const (
+	// DecoratorTracing is the decorator kind wrapping methods with Datadog spans.
+	DecoratorTracing = "tracing"
+
+	// DecoratorLogging is the decorator kind wrapping methods with log/slog records.
+	DecoratorLogging = "logging"
+
+	// DecoratorTimeout is the decorator kind applying per-method deadlines to the context.
+	DecoratorTimeout = "timeout"
+
+	// DecoratorRetry is the decorator kind retrying failed calls.
+	DecoratorRetry = "retry"
+
+	// DecoratorCircuitBreaker is the decorator kind rejecting calls after repeated failures.
+	DecoratorCircuitBreaker = "circuit-breaker"
+
+	// decoratorChain is the New<Interface>Chain constructor composing the configured decorators.
+	// It is generated automatically and cannot be listed in the config.
+	decoratorChain = "chain"
+)
+
+// decoratorTemplates maps decorator kinds to their body templates.
+var decoratorTemplates = map[string]string{
+	DecoratorTracing:        datadogTemplate,
+	DecoratorLogging:        loggingTemplate,
+	DecoratorTimeout:        timeoutTemplate,
+	DecoratorRetry:          retryTemplate,
+	DecoratorCircuitBreaker: circuitBreakerTemplate,
+	decoratorChain:          chainTemplate,
+}
+
+// parseTemplates parses the header template and the body templates of all decorator kinds.
+func parseTemplates() (*template.Template, map[string]*template.Template, error) {
+	headerTmpl, err := template.New("header").Funcs(helperFuncs).Parse(minimalHeaderTemplate)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse header template")
+	}
+
+	bodyTmpls := make(map[string]*template.Template, len(decoratorTemplates))
+	for kind, body := range decoratorTemplates {
+		tmpl, err := template.New(kind).Funcs(helperFuncs).Parse(body)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse %s template", kind)
+		}
+		bodyTmpls[kind] = tmpl
+	}
+
+	return headerTmpl, bodyTmpls, nil
+}
+
 var helperFuncs template.FuncMap
 
 func init() {
@@ -73,6 +447,28 @@ func init() {
 	helperFuncs["downFirst"] = downFirst
 	helperFuncs["replace"] = strings.ReplaceAll
 	helperFuncs["snake"] = toSnakeCase
+	helperFuncs["duration"] = durationLiteral
+}
+
+// durationLiteral returns a Go expression for d using the largest exact time unit,
+// e.g. "2 * time.Second" or "1500 * time.Millisecond".
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", int64(d))
 }
 
 func upFirst(s string) string {
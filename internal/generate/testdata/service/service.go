@@ -0,0 +1,15 @@
+package service
+
+import "context"
+
+type User struct {
+	ID   string
+	Name string
+}
+
+type UserService interface {
+	//ddtrace:timeout 2s
+	GetUser(ctx context.Context, id string) (*User, error)
+	CreateUser(ctx context.Context, name string, password string) (*User, error)
+	Name() string
+}
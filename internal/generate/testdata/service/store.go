@@ -0,0 +1,15 @@
+package service
+
+import "context"
+
+//ddtrace:ignore
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// Empty has no methods to decorate.
+type Empty interface{}
+
+type internalStore interface {
+	load(ctx context.Context) error
+}
@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// Conn declares its own Unwrap, so generated decorators must not add one.
+type Conn interface {
+	Ping(ctx context.Context) error
+	Unwrap() any
+}
@@ -0,0 +1,383 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+// defaultWatchDelay is how long --watch waits for file events to settle before regenerating.
+const defaultWatchDelay = 200 * time.Millisecond
+
+// watchSession is the state of gen --watch between regenerations.
+type watchSession struct {
+	gc         *GenerateCommand
+	configPath string
+	stdout     io.Writer
+	watcher    *fsnotify.Watcher
+
+	cfg     *config.Config
+	md      *moduleDeps
+	byDir   map[string]config.ResolvedPackage
+	watched map[string]bool
+
+	// trees are the directories "/..." patterns of the config are expanded in, watched
+	// so packages added to them are generated.
+	trees map[string]bool
+
+	// deps maps the import paths of the generated packages to the dependencies
+	// recorded in their manifests, and depDirs the directories of the dependencies
+	// built from local sources to their import paths.
+	deps    map[string][]string
+	depDirs map[string][]string
+}
+
+// runWatch generates the packages of the config, then regenerates the packages
+// whose sources or dependencies change and every package when the config changes,
+// until ctx is done.
+func (gc *GenerateCommand) runWatch(ctx context.Context, configPath string, stdout io.Writer) error {
+	configPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve config path")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "failed to start file watcher")
+	}
+	defer watcher.Close()
+
+	ws := &watchSession{
+		gc:         gc,
+		configPath: configPath,
+		stdout:     stdout,
+		watcher:    watcher,
+		watched:    map[string]bool{},
+	}
+	if err := ws.reload(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	gc.report = &Report{}
+	err = gc.runWithConfig(ws.cfg, configPath, stdout)
+	ws.logRun(start, err)
+	ws.trackDeps()
+	ws.logf("watching %d packages for changes", len(ws.byDir))
+
+	delay := gc.watchDelay
+	if delay <= 0 {
+		delay = defaultWatchDelay
+	}
+
+	var (
+		settled       <-chan time.Time
+		changedDirs   = map[string]bool{}
+		configChanged bool
+		rescan        bool
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+
+			switch {
+			case filepath.Clean(ev.Name) == configPath:
+				configChanged = true
+			case ws.isSource(ev.Name):
+				changedDirs[filepath.Dir(ev.Name)] = true
+			case ev.Has(fsnotify.Create) && ws.mayAddPackage(ev.Name):
+				rescan = true
+			default:
+				continue
+			}
+			settled = time.After(delay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			ws.logf("watch error: %v", err)
+
+		case <-settled:
+			settled = nil
+
+			if configChanged {
+				configChanged, rescan = false, false
+				clear(changedDirs)
+
+				if err := ws.reload(); err != nil {
+					ws.logf("%v", err)
+					continue
+				}
+				ws.regenerate(ws.packages(nil), nil)
+				continue
+			}
+
+			if rescan {
+				rescan = false
+
+				// Expanding the patterns again finds the packages added to their directories.
+				known := ws.byDir
+				if err := ws.reload(); err != nil {
+					ws.logf("%v", err)
+					continue
+				}
+				for dir := range ws.byDir {
+					if _, ok := known[dir]; !ok {
+						changedDirs[dir] = true
+					}
+				}
+			}
+
+			ws.regenerate(ws.affected(changedDirs))
+			clear(changedDirs)
+		}
+	}
+}
+
+// reload loads the config, resolves its packages and updates the watched directories.
+func (ws *watchSession) reload() error {
+	cfg, err := config.Load(ws.configPath)
+	if err != nil {
+		return err
+	}
+	if err := ws.gc.applyFlags(cfg); err != nil {
+		return err
+	}
+
+	resolved, err := cfg.ResolvePackages()
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve packages from config")
+	}
+
+	md, err := loadModuleDeps(filepath.Dir(ws.configPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to find module root")
+	}
+
+	byDir := make(map[string]config.ResolvedPackage, len(resolved))
+	for _, rp := range resolved {
+		if dir := md.sourceDir(rp.ImportPath); dir != "" {
+			byDir[dir] = rp
+		}
+	}
+
+	trees := map[string]bool{}
+	for pattern := range cfg.Packages {
+		if !strings.HasSuffix(pattern, "/...") {
+			continue
+		}
+		dirs, err := md.ws.PatternDirs(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve pattern %q", pattern)
+		}
+		for _, dir := range dirs {
+			trees[dir] = true
+		}
+	}
+
+	ws.cfg, ws.md, ws.byDir, ws.trees = cfg, md, byDir, trees
+	ws.watchDirs()
+	return nil
+}
+
+// trackDeps reads the dependencies of the generated packages from their manifests
+// and watches the directories of those built from local sources.
+func (ws *watchSession) trackDeps() {
+	ws.deps, ws.depDirs = map[string][]string{}, map[string][]string{}
+	for srcDir, rp := range ws.byDir {
+		m, err := readManifest(filepath.Join(packageOutputDir(srcDir, rp.Config), ManifestFile))
+		if err != nil {
+			continue
+		}
+
+		var deps []string
+		for _, entries := range m {
+			for _, e := range entries {
+				if e.Kind == entryDep {
+					deps = append(deps, e.Name)
+				}
+			}
+		}
+		slices.Sort(deps)
+		deps = slices.Compact(deps)
+		ws.deps[rp.ImportPath] = deps
+
+		for _, dep := range deps {
+			if dir := ws.md.ws.Dir(dep); dir != "" && !slices.Contains(ws.depDirs[dir], dep) {
+				ws.depDirs[dir] = append(ws.depDirs[dir], dep)
+			}
+		}
+	}
+	ws.watchDirs()
+}
+
+// watchDirs watches the config directory, the generated packages, their local
+// dependencies and the directories of the patterns, and stops watching any other directory.
+func (ws *watchSession) watchDirs() {
+	configDir := filepath.Dir(ws.configPath)
+	want := func(dir string) bool {
+		_, pkg := ws.byDir[dir]
+		_, dep := ws.depDirs[dir]
+		return pkg || dep || ws.trees[dir] || dir == configDir
+	}
+
+	for dir := range ws.watched {
+		if !want(dir) {
+			_ = ws.watcher.Remove(dir)
+			delete(ws.watched, dir)
+		}
+	}
+
+	dirs := []string{configDir}
+	for dir := range ws.byDir {
+		dirs = append(dirs, dir)
+	}
+	for dir := range ws.depDirs {
+		dirs = append(dirs, dir)
+	}
+	for dir := range ws.trees {
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range dirs {
+		if ws.watched[dir] {
+			continue
+		}
+		if err := ws.watcher.Add(dir); err != nil {
+			ws.logf("can't watch %s: %v", dir, err)
+			continue
+		}
+		ws.watched[dir] = true
+	}
+}
+
+// isSource reports whether name is a hand-written Go file of a watched package or dependency.
+func (ws *watchSession) isSource(name string) bool {
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, TraceSuffix) || strings.HasSuffix(name, TestSuffix) {
+		return false
+	}
+	dir := filepath.Dir(name)
+	_, pkg := ws.byDir[dir]
+	_, dep := ws.depDirs[dir]
+	return pkg || dep
+}
+
+// mayAddPackage reports whether the created file or directory name may add a package
+// matched by a pattern: a directory or a hand-written Go file in a directory of a pattern.
+func (ws *watchSession) mayAddPackage(name string) bool {
+	if !ws.trees[filepath.Dir(name)] {
+		return false
+	}
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		return true
+	}
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, TraceSuffix) && !strings.HasSuffix(name, TestSuffix)
+}
+
+// packages returns the resolved packages of dirs, or all of them if dirs is nil.
+func (ws *watchSession) packages(dirs map[string]bool) []config.ResolvedPackage {
+	var pkgs []config.ResolvedPackage
+	for dir, rp := range ws.byDir {
+		if dirs == nil || dirs[dir] {
+			pkgs = append(pkgs, rp)
+		}
+	}
+	return pkgs
+}
+
+// affected returns the packages built from dirs or depending on a package built from
+// dirs, and the import paths of the packages built from dirs.
+func (ws *watchSession) affected(dirs map[string]bool) ([]config.ResolvedPackage, []string) {
+	var changed []string
+	for dir := range dirs {
+		if rp, ok := ws.byDir[dir]; ok {
+			changed = append(changed, rp.ImportPath)
+		}
+		changed = append(changed, ws.depDirs[dir]...)
+	}
+
+	var pkgs []config.ResolvedPackage
+	for _, rp := range ws.byDir {
+		if slices.Contains(changed, rp.ImportPath) || slices.ContainsFunc(ws.deps[rp.ImportPath], func(dep string) bool { return slices.Contains(changed, dep) }) {
+			pkgs = append(pkgs, rp)
+		}
+	}
+	return pkgs, changed
+}
+
+// regenerate regenerates pkgs, dropping them and the changed import paths from the
+// package cache first.
+func (ws *watchSession) regenerate(pkgs []config.ResolvedPackage, changed []string) {
+	if len(pkgs) == 0 {
+		return
+	}
+
+	if ws.gc.pkgCache != nil {
+		for _, rp := range pkgs {
+			ws.gc.pkgCache.Invalidate(rp.ImportPath)
+		}
+		ws.gc.pkgCache.Invalidate(changed...)
+	}
+
+	start := time.Now()
+	ws.gc.report = &Report{}
+	err := ws.gc.generatePackages(ws.cfg, ws.configPath, pkgs)
+	ws.logRun(start, err)
+	ws.trackDeps()
+}
+
+// logRun prints a line per regenerated or failed package of the last run.
+func (ws *watchSession) logRun(start time.Time, err error) {
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	ws.gc.report.finish()
+
+	logged := false
+	for _, pr := range ws.gc.report.Packages {
+		switch pr.Status {
+		case StatusProcessed:
+			counts := map[string]int{}
+			for _, f := range pr.Files {
+				counts[f.Status]++
+			}
+			if counts[StatusRemoved] > 0 {
+				ws.logf("regenerated %s: %d written, %d unchanged, %d removed (%s)", pr.ImportPath, counts[StatusWritten], counts[StatusUnchanged], counts[StatusRemoved], elapsed)
+			} else {
+				ws.logf("regenerated %s: %d written, %d unchanged (%s)", pr.ImportPath, counts[StatusWritten], counts[StatusUnchanged], elapsed)
+			}
+			logged = true
+		case StatusError:
+			for _, e := range pr.Errors {
+				ws.logf("failed %s: %s", pr.ImportPath, e)
+			}
+			logged = true
+		}
+	}
+
+	if err != nil && !logged {
+		ws.logf("%v", err)
+	}
+}
+
+func (ws *watchSession) logf(format string, args ...any) {
+	fmt.Fprintf(ws.stdout, "%s %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,187 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+const watchedService = `package service
+
+import "context"
+
+type UserService interface {
+	GetUser(ctx context.Context, id string) error
+}
+`
+
+func TestGenerateCommand_RunWatch(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+		"service/service.go": watchedService,
+	})
+
+	cmd := NewGenerateCommand()
+	cmd.watchDelay = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- cmd.runWatch(ctx, filepath.Join(dir, config.FileName), &out) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "watching 1 packages") }, 10*time.Second, 10*time.Millisecond)
+	assert.Contains(t, out.String(), "regenerated example.com/app/service: 1 written, 0 unchanged")
+
+	changed := strings.Replace(watchedService, "}\n", "\tDeleteUser(ctx context.Context, id string) error\n}\n", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service", "service.go"), []byte(changed), 0o644))
+
+	tracePath := filepath.Join(dir, "service", "trace", "service_trace.go")
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(tracePath)
+		return err == nil && strings.Contains(string(content), "func (_d UserServiceWithTracing) DeleteUser(")
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return strings.Count(out.String(), "regenerated example.com/app/service") == 2 }, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestGenerateCommand_Run_WatchFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "single package", args: []string{"--watch", "-p", "./"}},
+		{name: "json report", args: []string{"--watch", "--format", "json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewGenerateCommand().Run(tt.args, &bytes.Buffer{})
+			assert.IsType(t, cli.CommandLineError(""), err)
+		})
+	}
+}
+
+const watchedBase = `package base
+
+import "context"
+
+type Base interface {
+	Ping(ctx context.Context) error
+}
+`
+
+const watchedEmbedding = `package service
+
+import (
+	"context"
+
+	"example.com/app/base"
+)
+
+type UserService interface {
+	base.Base
+	GetUser(ctx context.Context, id string) error
+}
+`
+
+func TestGenerateCommand_RunWatch_Dependency(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/service:\n",
+		"base/base.go":       watchedBase,
+		"service/service.go": watchedEmbedding,
+	})
+	chdir(t, dir)
+
+	cmd := NewGenerateCommand()
+	cmd.watchDelay = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- cmd.runWatch(ctx, filepath.Join(dir, config.FileName), &out) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "watching 1 packages") }, 10*time.Second, 10*time.Millisecond)
+
+	changed := strings.Replace(watchedBase, "}\n", "\tClose(ctx context.Context) error\n}\n", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base", "base.go"), []byte(changed), 0o644))
+
+	tracePath := filepath.Join(dir, "service", "trace", "service_trace.go")
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(tracePath)
+		return err == nil && strings.Contains(string(content), "func (_d UserServiceWithTracing) Close(")
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestGenerateCommand_RunWatch_NewPackage(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		config.FileName:      "output: trace\nno-generate: true\npackages:\n  example.com/app/...:\n",
+		"service/service.go": watchedService,
+	})
+	chdir(t, dir)
+
+	cmd := NewGenerateCommand()
+	cmd.watchDelay = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- cmd.runWatch(ctx, filepath.Join(dir, config.FileName), &out) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "watching 1 packages") }, 10*time.Second, 10*time.Millisecond)
+
+	// The directory is created first, and its source once the watch settled.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "orders", "store"), 0o755))
+	time.Sleep(100 * time.Millisecond)
+	store := strings.ReplaceAll(watchedService, "package service", "package store")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders", "store", "store.go"), []byte(store), 0o644))
+
+	tracePath := filepath.Join(dir, "orders", "store", "trace", "store_trace.go")
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(tracePath)
+		return err == nil && strings.Contains(string(content), "UserServiceWithTracing")
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "regenerated example.com/app/orders/store") }, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
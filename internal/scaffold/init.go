@@ -0,0 +1,234 @@
+package scaffold
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/cli"
+	"github.com/tuanvm-tyson/ddtrace/internal/codegen"
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+	"github.com/tuanvm-tyson/ddtrace/internal/scanner"
+)
+
+// defaultExcludes are always proposed: generated mocks, data transfer objects and test fixtures.
+var defaultExcludes = []string{"mock", "dto", "testdata"}
+
+// optionalExcludes are proposed when a package path of the module contains them.
+var optionalExcludes = []string{"mocks", "fake", "fakes"}
+
+// InitCommand implements cli.Command interface
+type InitCommand struct {
+	cli.BaseCommand
+
+	output string
+	force  bool
+
+	fs fileSystem
+}
+
+type fileSystem struct {
+	WriteFile func(string, []byte, os.FileMode) error
+	Stat      func(string) (os.FileInfo, error)
+}
+
+// NewInitCommand creates InitCommand
+func NewInitCommand() *InitCommand {
+	ic := &InitCommand{
+		fs: fileSystem{
+			WriteFile: os.WriteFile,
+			Stat:      os.Stat,
+		},
+	}
+
+	flags := &flag.FlagSet{}
+	flags.StringVar(&ic.output, "o", "trace", "output subdirectory written to the config")
+	flags.BoolVar(&ic.force, "force", false, "overwrite an existing "+config.FileName)
+
+	ic.BaseCommand = cli.BaseCommand{
+		Short: "scaffold " + config.FileName + " from the packages of the current module",
+		Usage: "[-o output_dir] [--force]",
+		Help: `
+Walks the current module, lists every package declaring interfaces with
+context-accepting methods and writes a commented ` + config.FileName + ` to the module root.
+`,
+		Flags: flags,
+	}
+
+	return ic
+}
+
+// Run implements cli.Command interface
+func (ic *InitCommand) Run(args []string, stdout io.Writer) error {
+	if err := ic.FlagSet().Parse(args); err != nil {
+		return cli.CommandLineError(err.Error())
+	}
+
+	modulePath, rootDir, err := config.FindModule()
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(rootDir, config.FileName)
+	if _, err := ic.fs.Stat(configPath); err == nil && !ic.force {
+		return errors.Errorf("%s already exists, use --force to overwrite it", configPath)
+	}
+
+	paths, err := config.ExpandPattern(modulePath + "/...")
+	if err != nil {
+		return err
+	}
+
+	data := scaffoldData{
+		Module:  modulePath,
+		Output:  ic.output,
+		Exclude: proposeExcludes(paths),
+	}
+
+	pkgCache := codegen.NewPackageCache(nil)
+	for _, path := range paths {
+		if hasSegment(path, ic.output) || slices.ContainsFunc(data.Exclude, func(seg string) bool { return hasSegment(path, seg) }) {
+			continue
+		}
+
+		// Directories without files for the current platform and tags, like tools packages,
+		// aren't packages; packages that fail to scan are left out of the proposal.
+		ifaces, err := contextInterfaces(modulePath, rootDir, path, pkgCache)
+		if errors.Is(err, scanner.ErrPackageNotFound) {
+			continue
+		}
+		if err != nil {
+			if _, err := fmt.Fprintf(stdout, "warning: failed to scan package %s: %v\n", path, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(ifaces) > 0 {
+			data.Packages = append(data.Packages, scaffoldPackage{ImportPath: path, Interfaces: ifaces})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := scaffoldTemplate.Execute(&buf, data); err != nil {
+		return errors.Wrap(err, "failed to render config")
+	}
+
+	if err := ic.fs.WriteFile(configPath, buf.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "failed to write config")
+	}
+
+	_, err = fmt.Fprintf(stdout, "wrote %s with %d packages\n", configPath, len(data.Packages))
+	return err
+}
+
+// contextInterfaces returns the names of the interfaces of a package having context-accepting methods,
+// including the methods of embedded interfaces. Interfaces whose method set can't be resolved are
+// left out, as gen skips them.
+func contextInterfaces(modulePath, rootDir, importPath string, pkgCache *codegen.PackageCache) ([]string, error) {
+	dir := filepath.Join(rootDir, filepath.FromSlash(strings.TrimPrefix(importPath, modulePath)))
+
+	pkg, err := scanner.BuildFromDir(importPath, dir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	astPkg, err := scanner.AST(fset, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	fileGroups, err := scanner.ScanPackage(astPkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, fg := range fileGroups {
+		for _, iface := range fg.Interfaces {
+			methods, _, err := codegen.InterfaceMethods(fset, pkg, astPkg, nil, iface.Name, pkgCache)
+			if err != nil {
+				continue
+			}
+			for _, m := range methods {
+				if m.AcceptsContext {
+					names = append(names, iface.Name)
+					break
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// proposeExcludes returns the default exclude entries plus the optional ones used by paths.
+func proposeExcludes(paths []string) []string {
+	excludes := slices.Clone(defaultExcludes)
+	for _, seg := range optionalExcludes {
+		if slices.ContainsFunc(paths, func(path string) bool { return hasSegment(path, seg) }) {
+			excludes = append(excludes, seg)
+		}
+	}
+	return excludes
+}
+
+// hasSegment reports whether seg is a path segment of importPath, like config.Exclude matching.
+func hasSegment(importPath, seg string) bool {
+	return slices.Contains(strings.Split(importPath, "/"), seg)
+}
+
+type scaffoldData struct {
+	Module   string
+	Output   string
+	Exclude  []string
+	Packages []scaffoldPackage
+}
+
+type scaffoldPackage struct {
+	ImportPath string
+	Interfaces []string
+}
+
+var scaffoldTemplate = template.Must(template.New("config").Funcs(template.FuncMap{"join": strings.Join}).Parse(`# ddtrace configuration for {{.Module}}, generated by "ddtrace init".
+# Run "ddtrace gen" from this directory to generate tracing decorators.
+
+# Output subdirectory relative to each source package.
+output: {{.Output}}
+
+# Don't write //go:generate tags; this file replaces them.
+no-generate: true
+
+# Path segments skipped when expanding "..." patterns (e.g. github.com/org/app/...).
+exclude:
+{{- range .Exclude}}
+  - {{.}}
+{{- end}}
+
+# Uncomment to enable additional decorators for every package.
+# metrics: true
+# logging: true
+
+# Packages declaring interfaces with context-accepting methods.
+# Add per-interface settings below a package, for example:
+#   interfaces:
+#     UserService:
+#       span-prefix: users
+#       decorators: [tracing, retry]
+packages:
+{{- range .Packages}}
+  # {{join .Interfaces ", "}}
+  {{.ImportPath}}:
+{{- else}}
+  # No packages with context-accepting interfaces were found.
+  # {{.Module}}/...:
+{{- end}}
+`))
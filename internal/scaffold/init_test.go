@@ -0,0 +1,137 @@
+package scaffold
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuanvm-tyson/ddtrace/internal/config"
+)
+
+// setupModule writes files (relative path -> content) into a temporary module
+// and makes it the working directory for the rest of the test.
+func setupModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/app\n\ngo 1.23\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) }) //nolint: errcheck
+
+	return dir
+}
+
+const contextService = `package service
+
+import "context"
+
+type UserService interface {
+	GetUser(ctx context.Context, id string) error
+}
+
+type Formatter interface {
+	Format(v any) string
+}
+`
+
+func TestInitCommand_Run(t *testing.T) {
+	dir := setupModule(t, map[string]string{
+		"service/service.go":       contextService,
+		"service/mock/mock.go":     "package mock\n\nimport \"context\"\n\ntype Mock interface {\n\tDo(ctx context.Context) error\n}\n",
+		"service/trace/x_trace.go": "package trace\n",
+		"util/util.go":             "package util\n\ntype Formatter interface {\n\tFormat(v any) string\n}\n",
+		"fakes/fakes.go":           "package fakes\n",
+		"nested/go.mod":            "module example.com/nested\n",
+		"nested/nested.go":         contextService,
+		"root.go":                  contextService,
+	})
+
+	cmd := NewInitCommand()
+	var stdout bytes.Buffer
+	require.NoError(t, cmd.Run(nil, &stdout))
+	assert.Contains(t, stdout.String(), "with 2 packages")
+
+	cfg, err := config.Load(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "trace", cfg.Output)
+	assert.True(t, cfg.NoGenerate)
+	assert.Equal(t, []string{"mock", "dto", "testdata", "fakes"}, cfg.Exclude)
+	assert.Equal(t, []string{"example.com/app", "example.com/app/service"}, slices.Sorted(maps.Keys(cfg.Packages)))
+
+	data, err := os.ReadFile(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  # UserService\n  example.com/app/service:\n")
+}
+
+func TestInitCommand_Run_EmbeddedContextMethods(t *testing.T) {
+	dir := setupModule(t, map[string]string{
+		"base/base.go":       "package base\n\nimport \"context\"\n\ntype Pinger interface {\n\tPing(ctx context.Context) error\n}\n",
+		"service/service.go": "package service\n\nimport (\n\t\"io\"\n\n\t\"example.com/app/base\"\n)\n\ntype Store interface {\n\tbase.Pinger\n\tio.Closer\n}\n\ntype Closer interface {\n\tio.Closer\n}\n",
+	})
+
+	require.NoError(t, NewInitCommand().Run(nil, &bytes.Buffer{}))
+
+	cfg, err := config.Load(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/app/base", "example.com/app/service"}, slices.Sorted(maps.Keys(cfg.Packages)))
+
+	data, err := os.ReadFile(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  # Store\n  example.com/app/service:\n")
+}
+
+func TestInitCommand_Run_UnscannablePackages(t *testing.T) {
+	dir := setupModule(t, map[string]string{
+		"service/service.go": contextService,
+		"tools/tools.go":     "//go:build tools\n\npackage tools\n\nimport _ \"golang.org/x/tools/cmd/stringer\"\n",
+		"broken/broken.go":   "package broken\n\nfunc {\n",
+	})
+
+	var stdout bytes.Buffer
+	require.NoError(t, NewInitCommand().Run(nil, &stdout))
+	assert.Contains(t, stdout.String(), "warning: failed to scan package example.com/app/broken: ")
+	assert.NotContains(t, stdout.String(), "example.com/app/tools")
+	assert.Contains(t, stdout.String(), "with 1 packages")
+
+	cfg, err := config.Load(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/app/service"}, slices.Sorted(maps.Keys(cfg.Packages)))
+}
+
+func TestInitCommand_Run_ExistingConfig(t *testing.T) {
+	dir := setupModule(t, map[string]string{
+		"service/service.go": contextService,
+		config.FileName:      "output: custom\n",
+	})
+
+	cmd := NewInitCommand()
+	err := cmd.Run(nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	data, err := os.ReadFile(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "output: custom\n", string(data))
+
+	cmd = NewInitCommand()
+	require.NoError(t, cmd.Run([]string{"--force", "-o", "traced"}, &bytes.Buffer{}))
+
+	cfg, err := config.Load(filepath.Join(dir, config.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "traced", cfg.Output)
+	assert.Contains(t, cfg.Packages, "example.com/app/service")
+}
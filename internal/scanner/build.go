@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"path/filepath"
+	"strings"
+)
+
+// Platform returns the GOOS/GOARCH files are matched against.
+func Platform() string {
+	return build.Default.GOOS + "/" + build.Default.GOARCH
+}
+
+// buildContext returns the context selecting the files of a package like the go command
+// does: by the GOOS and GOARCH suffixes of their names and their //go:build constraints,
+// which are matched against tags in addition to GOOS, GOARCH and the Go release.
+func buildContext(tags []string) build.Context {
+	ctx := build.Default
+	ctx.BuildTags = tags
+	return ctx
+}
+
+// buildFlags returns the go list flags passing the build tags.
+func buildFlags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(tags, ",")}
+}
+
+// fileConstraint returns the build constraint of a source file: its //go:build line
+// combined with the GOOS and GOARCH implied by its name, or nil if it has none.
+func fileConstraint(filename string, f *ast.File) constraint.Expr {
+	var expr constraint.Expr
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			if e, err := constraint.Parse(c.Text); err == nil {
+				expr = e
+			}
+		}
+	}
+
+	name := nameExpr(filename)
+	switch {
+	case name == nil:
+		return expr
+	case expr == nil:
+		return name
+	}
+	return &constraint.AndExpr{X: expr, Y: name}
+}
+
+// nameExpr returns the GOOS and GOARCH constraint implied by a file name, or nil.
+func nameExpr(filename string) constraint.Expr {
+	var expr constraint.Expr
+	goos, goarch := nameConstraint(filename)
+	for _, tag := range []string{goos, goarch} {
+		if tag == "" {
+			continue
+		}
+		if expr == nil {
+			expr = &constraint.TagExpr{Tag: tag}
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: &constraint.TagExpr{Tag: tag}}
+		}
+	}
+	return expr
+}
+
+// nameConstraint returns the GOOS and GOARCH a file name is restricted to by its
+// *_GOOS, *_GOARCH or *_GOOS_GOARCH suffix, ignoring a _test suffix.
+func nameConstraint(filename string) (goos, goarch string) {
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(filename), ".go"), "_test")
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[i:]
+	} else {
+		return "", ""
+	}
+
+	parts := strings.Split(name, "_")
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2], parts[n-1]
+	}
+	if n >= 1 {
+		if knownOS[parts[n-1]] {
+			return parts[n-1], ""
+		}
+		if knownArch[parts[n-1]] {
+			return "", parts[n-1]
+		}
+	}
+	return "", ""
+}
+
+// knownOS and knownArch are the GOOS and GOARCH values recognized in file names,
+// as listed in go/build.
+var knownOS = setOf("aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js",
+	"linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos")
+
+var knownArch = setOf("386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips",
+	"mipsle", "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv",
+	"riscv64", "s390", "s390x", "sparc", "sparc64", "wasm")
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
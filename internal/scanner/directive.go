@@ -0,0 +1,175 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const directivePrefix = "//ddtrace:"
+
+// Directives are the //ddtrace: comment directives of an interface or a method.
+type Directives struct {
+	// Ignore is set by //ddtrace:ignore.
+	Ignore bool
+
+	// Trace is set by //ddtrace:trace, on interfaces only. It opts an interface in
+	// when only annotated interfaces are generated (see Filter.AnnotatedOnly).
+	Trace bool
+
+	// Timeout is set by //ddtrace:timeout <duration>, on methods only.
+	Timeout time.Duration
+
+	// Span holds the settings of the spans started by tracing decorators.
+	Span SpanDirectives
+}
+
+// SpanDirectives are the directives configuring the spans of tracing decorators.
+type SpanDirectives struct {
+	// Name is set by //ddtrace:name: the span name prefix of the methods of an
+	// interface, or the whole span name of a method.
+	Name string
+
+	// Resource is set by //ddtrace:resource, the resource name of the spans.
+	Resource string
+
+	// Service is set by //ddtrace:service, the service name of the spans.
+	Service string
+
+	// Type is set by //ddtrace:type, the span type (e.g. db, cache, http).
+	Type string
+
+	// Tags are set by //ddtrace:tag key=param directives.
+	Tags []SpanTag
+}
+
+// SpanTag tags spans with the value of a method parameter.
+type SpanTag struct {
+	Key   string
+	Param string
+}
+
+// Merge returns d with the settings it doesn't set taken from defaults. Tags of
+// defaults are kept unless d has a tag with the same key.
+func (d SpanDirectives) Merge(defaults SpanDirectives) SpanDirectives {
+	if d.Name == "" {
+		d.Name = defaults.Name
+	}
+	if d.Resource == "" {
+		d.Resource = defaults.Resource
+	}
+	if d.Service == "" {
+		d.Service = defaults.Service
+	}
+	if d.Type == "" {
+		d.Type = defaults.Type
+	}
+
+	var tags []SpanTag
+	for _, dt := range defaults.Tags {
+		if !d.hasTag(dt.Key) {
+			tags = append(tags, dt)
+		}
+	}
+	d.Tags = append(tags, d.Tags...)
+	return d
+}
+
+func (d SpanDirectives) hasTag(key string) bool {
+	for _, t := range d.Tags {
+		if t.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDirectives parses the //ddtrace: directives of comment groups, which may be nil.
+// Unknown directives, directives with invalid arguments and, unless method is set,
+// directives only supported on methods are errors.
+func ParseDirectives(method bool, groups ...*ast.CommentGroup) (Directives, error) {
+	var d Directives
+	for _, cg := range groups {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			text, ok := strings.CutPrefix(c.Text, directivePrefix)
+			if !ok {
+				continue
+			}
+			if err := d.parse(method, strings.TrimSpace(text)); err != nil {
+				return d, fmt.Errorf("%q: %w", strings.TrimSpace(c.Text), err)
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *Directives) parse(method bool, text string) error {
+	// The name ends at the first space or tab; arguments like resources keep their inner spacing.
+	name, arg := text, ""
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		name, arg = text[:i], strings.TrimSpace(text[i:])
+	}
+
+	switch name {
+	case "ignore":
+		if arg != "" {
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+		d.Ignore = true
+		return nil
+	case "trace":
+		if method {
+			return fmt.Errorf("%s directive is only supported on interfaces", name)
+		}
+		if arg != "" {
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+		d.Trace = true
+		return nil
+	case "timeout":
+		if !method {
+			return fmt.Errorf("%s directive is only supported on methods", name)
+		}
+		timeout, err := time.ParseDuration(arg)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid duration %q", arg)
+		}
+		d.Timeout = timeout
+		return nil
+	case "resource":
+		// Resource names often contain spaces, like SQL queries.
+		if arg == "" {
+			return fmt.Errorf("missing %s", name)
+		}
+		d.Span.Resource = arg
+		return nil
+	case "name", "service", "type":
+		if arg == "" || strings.ContainsFunc(arg, unicode.IsSpace) {
+			return fmt.Errorf("%s must be a single word, got %q", name, arg)
+		}
+		switch name {
+		case "name":
+			d.Span.Name = arg
+		case "service":
+			d.Span.Service = arg
+		default:
+			d.Span.Type = arg
+		}
+		return nil
+	case "tag":
+		key, param, ok := strings.Cut(arg, "=")
+		if !ok || key == "" || param == "" || strings.ContainsFunc(arg, unicode.IsSpace) {
+			return fmt.Errorf("tag must be key=param, got %q", arg)
+		}
+		d.Span.Tags = append(d.Span.Tags, SpanTag{Key: key, Param: param})
+		return nil
+	default:
+		return errors.New("unknown directive")
+	}
+}
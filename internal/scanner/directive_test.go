@@ -0,0 +1,175 @@
+package scanner
+
+import (
+	"go/ast"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   bool
+		comments []string
+		want     Directives
+		wantErr  string
+	}{
+		{
+			name:     "plain comments",
+			comments: []string{"// UserService manages users.", "// ddtrace: not a directive"},
+		},
+		{
+			name: "span directives",
+			comments: []string{
+				"//ddtrace:name users",
+				"//ddtrace:resource SELECT * FROM users",
+				"//ddtrace:service users-db",
+				"//ddtrace:type db",
+				"//ddtrace:tag user.id=id",
+				"//ddtrace:tag tenant=tenantID",
+			},
+			want: Directives{Span: SpanDirectives{
+				Name:     "users",
+				Resource: "SELECT * FROM users",
+				Service:  "users-db",
+				Type:     "db",
+				Tags:     []SpanTag{{Key: "user.id", Param: "id"}, {Key: "tenant", Param: "tenantID"}},
+			}},
+		},
+		{
+			name: "tab separated",
+			comments: []string{
+				"//ddtrace:type\tdb",
+				"//ddtrace:resource\tSELECT *  FROM users",
+				"//ddtrace:tag \t user.id=id",
+			},
+			want: Directives{Span: SpanDirectives{
+				Resource: "SELECT *  FROM users",
+				Type:     "db",
+				Tags:     []SpanTag{{Key: "user.id", Param: "id"}},
+			}},
+		},
+		{
+			name:     "tab separated ignore",
+			method:   true,
+			comments: []string{"//ddtrace:ignore\t", "//ddtrace:timeout\t2s"},
+			want:     Directives{Ignore: true, Timeout: 2 * time.Second},
+		},
+		{
+			name:     "method directives",
+			method:   true,
+			comments: []string{"//ddtrace:ignore", "//ddtrace:timeout 2s"},
+			want:     Directives{Ignore: true, Timeout: 2 * time.Second},
+		},
+		{
+			name:     "trace",
+			comments: []string{"//ddtrace:trace"},
+			want:     Directives{Trace: true},
+		},
+		{
+			name:     "trace on method",
+			method:   true,
+			comments: []string{"//ddtrace:trace"},
+			wantErr:  "trace directive is only supported on interfaces",
+		},
+		{
+			name:     "unknown",
+			comments: []string{"//ddtrace:sample 0.5"},
+			wantErr:  `"//ddtrace:sample 0.5": unknown directive`,
+		},
+		{
+			name:     "timeout on interface",
+			comments: []string{"//ddtrace:timeout 2s"},
+			wantErr:  "timeout directive is only supported on methods",
+		},
+		{
+			name:     "invalid timeout",
+			method:   true,
+			comments: []string{"//ddtrace:timeout soon"},
+			wantErr:  `invalid duration "soon"`,
+		},
+		{
+			name:     "ignore with argument",
+			comments: []string{"//ddtrace:ignore please"},
+			wantErr:  `unexpected argument "please"`,
+		},
+		{
+			name:     "name with spaces",
+			comments: []string{"//ddtrace:name user service"},
+			wantErr:  `name must be a single word, got "user service"`,
+		},
+		{
+			name:     "missing type",
+			comments: []string{"//ddtrace:type"},
+			wantErr:  `type must be a single word, got ""`,
+		},
+		{
+			name:     "missing resource",
+			comments: []string{"//ddtrace:resource"},
+			wantErr:  "missing resource",
+		},
+		{
+			name:     "tag without param",
+			comments: []string{"//ddtrace:tag user.id"},
+			wantErr:  `tag must be key=param, got "user.id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cg := &ast.CommentGroup{}
+			for _, text := range tt.comments {
+				cg.List = append(cg.List, &ast.Comment{Text: text})
+			}
+
+			got, err := ParseDirectives(tt.method, nil, cg)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpanDirectives_Merge(t *testing.T) {
+	iface := SpanDirectives{
+		Name:    "users",
+		Service: "users-db",
+		Type:    "db",
+		Tags:    []SpanTag{{Key: "tenant", Param: "tenantID"}, {Key: "id", Param: "id"}},
+	}
+	method := SpanDirectives{
+		Resource: "GetUser",
+		Type:     "cache",
+		Tags:     []SpanTag{{Key: "id", Param: "userID"}},
+	}
+
+	assert.Equal(t, SpanDirectives{
+		Name:     "users",
+		Resource: "GetUser",
+		Service:  "users-db",
+		Type:     "cache",
+		Tags:     []SpanTag{{Key: "tenant", Param: "tenantID"}, {Key: "id", Param: "userID"}},
+	}, method.Merge(iface))
+}
+
+func TestScanPackage_InvalidDirective(t *testing.T) {
+	p := parseSource(t, "service.go", `
+package testpkg
+
+//ddtrace:trace-all
+type UserService interface {
+	Get()
+}
+`)
+
+	_, err := ScanPackage(p)
+	require.Error(t, err)
+	assert.Equal(t, `service.go: interface UserService: "//ddtrace:trace-all": unknown directive`, err.Error())
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+)
+
+// Filter selects the discovered interfaces decorators are generated for. The zero
+// Filter keeps every interface.
+type Filter struct {
+	// AnnotatedOnly keeps only interfaces annotated with //ddtrace:trace.
+	AnnotatedOnly bool
+
+	// Include keeps only interfaces whose name matches one of the patterns, if any.
+	Include []Pattern
+
+	// Exclude drops interfaces whose name matches one of the patterns.
+	Exclude []Pattern
+
+	// OnlyWithContextMethods drops interfaces without a method accepting a context.Context.
+	OnlyWithContextMethods bool
+
+	// MinMethods drops interfaces with fewer methods, embedded ones included.
+	MinMethods int
+
+	// ExportedOnly drops unexported interfaces.
+	ExportedOnly bool
+
+	// ExcludeEmbeddingOnly drops interfaces declaring no method of their own, only embedding others.
+	ExcludeEmbeddingOnly bool
+}
+
+// Pattern is a regular expression matching whole interface names.
+type Pattern struct {
+	expr string
+	re   *regexp.Regexp
+}
+
+// CompilePattern compiles expr into a Pattern anchored at both ends, so "Service"
+// matches the Service interface only and ".*Service" every name ending in Service.
+func CompilePattern(expr string) (Pattern, error) {
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return Pattern{}, err
+	}
+	return Pattern{expr: expr, re: re}, nil
+}
+
+// MatchString reports whether name matches the pattern as a whole.
+func (p Pattern) MatchString(name string) bool {
+	return p.re.MatchString(name)
+}
+
+// String returns the expression the pattern was compiled from.
+func (p Pattern) String() string {
+	return p.expr
+}
+
+// NeedsMethods reports whether the filter depends on the method sets of interfaces.
+func (f Filter) NeedsMethods() bool {
+	return f.OnlyWithContextMethods || f.MinMethods > 0
+}
+
+// Skip returns the reason iface is filtered out, or "" if it's kept. methods is the
+// method set of iface including embedded interfaces; it's only used when NeedsMethods
+// reports true.
+func (f Filter) Skip(iface InterfaceInfo, methods []MethodInfo) string {
+	if f.AnnotatedOnly && !iface.Annotated {
+		return "not annotated with //ddtrace:trace"
+	}
+	if f.ExportedOnly && !token.IsExported(iface.Name) {
+		return "unexported interface"
+	}
+	if len(f.Include) > 0 && !matchAny(f.Include, iface.Name) {
+		return "name matches no include pattern"
+	}
+	for _, p := range f.Exclude {
+		if p.MatchString(iface.Name) {
+			return fmt.Sprintf("name matches exclude pattern %s", p)
+		}
+	}
+	if f.ExcludeEmbeddingOnly && len(iface.Methods) == 0 && iface.Embeds > 0 {
+		return "interface only embeds other interfaces"
+	}
+
+	if f.MinMethods > 0 && len(methods) < f.MinMethods {
+		return fmt.Sprintf("fewer than %d methods", f.MinMethods)
+	}
+	if f.OnlyWithContextMethods && !(InterfaceInfo{Methods: methods}).HasContextMethods() {
+		return "no method accepts a context.Context"
+	}
+	return ""
+}
+
+func matchAny(patterns []Pattern, name string) bool {
+	for _, p := range patterns {
+		if p.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Skip(t *testing.T) {
+	ctxMethod := MethodInfo{Name: "Get", AcceptsContext: true}
+	plainMethod := MethodInfo{Name: "Close"}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		iface   InterfaceInfo
+		methods []MethodInfo
+		want    string
+	}{
+		{
+			name:  "zero filter",
+			iface: InterfaceInfo{Name: "store"},
+		},
+		{
+			name:   "annotated only",
+			filter: Filter{AnnotatedOnly: true},
+			iface:  InterfaceInfo{Name: "Store"},
+			want:   "not annotated with //ddtrace:trace",
+		},
+		{
+			name:   "annotated",
+			filter: Filter{AnnotatedOnly: true},
+			iface:  InterfaceInfo{Name: "Store", Annotated: true},
+		},
+		{
+			name:   "exported only",
+			filter: Filter{ExportedOnly: true},
+			iface:  InterfaceInfo{Name: "store"},
+			want:   "unexported interface",
+		},
+		{
+			name:   "include",
+			filter: Filter{Include: patterns(t, ".*Service", "Repo.*")},
+			iface:  InterfaceInfo{Name: "RepoStore"},
+		},
+		{
+			name:   "include mismatch",
+			filter: Filter{Include: patterns(t, ".*Service")},
+			iface:  InterfaceInfo{Name: "Closer"},
+			want:   "name matches no include pattern",
+		},
+		{
+			name:   "exclude",
+			filter: Filter{Include: patterns(t, ".*Service"), Exclude: patterns(t, "Mock.*")},
+			iface:  InterfaceInfo{Name: "MockService"},
+			want:   "name matches exclude pattern Mock.*",
+		},
+		{
+			name:   "patterns match whole names",
+			filter: Filter{Include: patterns(t, "Service", "UserService"), Exclude: patterns(t, "User")},
+			iface:  InterfaceInfo{Name: "UserService"},
+		},
+		{
+			name:   "include substring",
+			filter: Filter{Include: patterns(t, "Service")},
+			iface:  InterfaceInfo{Name: "UserService"},
+			want:   "name matches no include pattern",
+		},
+		{
+			name:   "embedding only",
+			filter: Filter{ExcludeEmbeddingOnly: true},
+			iface:  InterfaceInfo{Name: "ReadCloser", Embeds: 2},
+			want:   "interface only embeds other interfaces",
+		},
+		{
+			name:   "embedding and declaring",
+			filter: Filter{ExcludeEmbeddingOnly: true},
+			iface:  InterfaceInfo{Name: "Store", Methods: []MethodInfo{ctxMethod}, Embeds: 1},
+		},
+		{
+			name:    "min methods",
+			filter:  Filter{MinMethods: 2},
+			iface:   InterfaceInfo{Name: "Store", Methods: []MethodInfo{ctxMethod}},
+			methods: []MethodInfo{ctxMethod},
+			want:    "fewer than 2 methods",
+		},
+		{
+			name:    "min methods with embedded",
+			filter:  Filter{MinMethods: 2},
+			iface:   InterfaceInfo{Name: "Store", Methods: []MethodInfo{ctxMethod}, Embeds: 1},
+			methods: []MethodInfo{ctxMethod, plainMethod},
+		},
+		{
+			name:    "without context methods",
+			filter:  Filter{OnlyWithContextMethods: true},
+			iface:   InterfaceInfo{Name: "Closer", Embeds: 1},
+			methods: []MethodInfo{plainMethod},
+			want:    "no method accepts a context.Context",
+		},
+		{
+			name:    "context method of embedded interface",
+			filter:  Filter{OnlyWithContextMethods: true},
+			iface:   InterfaceInfo{Name: "Store", Methods: []MethodInfo{plainMethod}, Embeds: 1},
+			methods: []MethodInfo{plainMethod, ctxMethod},
+		},
+		{
+			name:   "empty method set",
+			filter: Filter{OnlyWithContextMethods: true, MinMethods: 1},
+			iface:  InterfaceInfo{Name: "Store", Embeds: 1},
+			want:   "fewer than 1 methods",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Skip(tt.iface, tt.methods))
+		})
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	p, err := CompilePattern("Get|List")
+	require.NoError(t, err)
+	assert.True(t, p.MatchString("Get"))
+	assert.False(t, p.MatchString("GetAll"))
+	assert.Equal(t, "Get|List", p.String())
+
+	_, err = CompilePattern("(")
+	assert.Error(t, err)
+}
+
+func patterns(t *testing.T, exprs ...string) []Pattern {
+	t.Helper()
+	res := make([]Pattern, 0, len(exprs))
+	for _, expr := range exprs {
+		p, err := CompilePattern(expr)
+		require.NoError(t, err)
+		res = append(res, p)
+	}
+	return res
+}
@@ -3,34 +3,41 @@ package scanner
 import (
 	"errors"
 	"go/ast"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
 
-var errPackageNotFound = errors.New("package not found")
+// ErrPackageNotFound is returned when a directory has no Go files of the package.
+var ErrPackageNotFound = errors.New("package not found")
 
 var loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps
 
+// typesMode type-checks packages and their dependencies from source, so loading doesn't
+// depend on export data matching the go/types version.
+var typesMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedImports | packages.NeedDeps
+
 type Package struct {
 	Name  string
 	Files map[string]*ast.File
 }
 
-// Load loads package by its import path
-func Load(path string) (*packages.Package, error) {
-	cfg := &packages.Config{Mode: loadMode}
+// Load loads package by its import path, selecting its files with the build tags.
+func Load(path string, tags []string) (*packages.Package, error) {
+	cfg := &packages.Config{Mode: loadMode, BuildFlags: buildFlags(tags)}
 	pkgs, err := packages.Load(cfg, path)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(pkgs) < 1 {
-		return nil, errPackageNotFound
+		return nil, ErrPackageNotFound
 	}
 
 	if len(pkgs[0].Errors) > 0 {
@@ -41,12 +48,12 @@ func Load(path string) (*packages.Package, error) {
 }
 
 // LoadAll loads multiple packages in a single batch call.
-func LoadAll(paths []string) (map[string]*packages.Package, error) {
+func LoadAll(paths []string, tags []string) (map[string]*packages.Package, error) {
 	if len(paths) == 0 {
 		return nil, nil
 	}
 
-	cfg := &packages.Config{Mode: loadMode}
+	cfg := &packages.Config{Mode: loadMode, BuildFlags: buildFlags(tags)}
 	pkgs, err := packages.Load(cfg, paths...)
 	if err != nil {
 		return nil, err
@@ -62,58 +69,131 @@ func LoadAll(paths []string) (map[string]*packages.Package, error) {
 	return result, nil
 }
 
-// BuildFromDir creates a *packages.Package from filesystem info without
-// invoking go list or downloading modules. It reads the directory to find
-// .go files and parses the package name from the first source file.
-func BuildFromDir(importPath, dir string) (*packages.Package, error) {
-	entries, err := os.ReadDir(dir)
+// LoadTypes type-checks pkgs, keyed by import path. Packages that failed to load or
+// type-check are returned with their Errors set so the caller can fall back to the AST.
+// Function bodies of dependencies are skipped; positions are recorded in fs.
+func LoadTypes(fs *token.FileSet, pkgs []*packages.Package, tags []string) (map[string]*packages.Package, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	paths := make([]string, 0, len(pkgs))
+	dirs := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		paths = append(paths, p.PkgPath)
+		dirs[Dir(p)] = true
+	}
+
+	cfg := &packages.Config{
+		Mode:       typesMode,
+		Fset:       fs,
+		BuildFlags: buildFlags(tags),
+		ParseFile: func(fs *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			if dirs[filepath.Dir(filename)] {
+				return parser.ParseFile(fs, filename, src, parser.AllErrors|parser.ParseComments)
+			}
+			return parseDeclarations(fs, filename, src)
+		},
+	}
+	loaded, err := packages.Load(cfg, paths...)
 	if err != nil {
 		return nil, err
 	}
 
-	var goFiles []string
-	for _, e := range entries {
-		name := e.Name()
-		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
-			continue
+	result := make(map[string]*packages.Package, len(loaded))
+	for _, p := range loaded {
+		result[p.PkgPath] = p
+	}
+	return result, nil
+}
+
+// parseDeclarations parses a file of a dependency without its function bodies,
+// which aren't needed to type-check the declarations.
+func parseDeclarations(fs *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	f, err := parser.ParseFile(fs, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			fd.Body = nil
 		}
-		goFiles = append(goFiles, filepath.Join(dir, name))
 	}
+	return f, nil
+}
 
-	if len(goFiles) == 0 {
-		return nil, errPackageNotFound
+// BuildFromDir creates a *packages.Package from filesystem info without
+// invoking go list or downloading modules. Files are selected like the go command
+// does: by the build constraints matched against tags, skipping test files, including
+// those of the external _test package, and files of a "documentation" package.
+// A directory with files of several packages returns a *build.MultiplePackageError.
+func BuildFromDir(importPath, dir string, tags []string) (*packages.Package, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, ErrPackageNotFound
 	}
 
-	// Parse just enough to get the package name.
-	f, err := parser.ParseFile(token.NewFileSet(), goFiles[0], nil, parser.PackageClauseOnly)
+	ctx := buildContext(tags)
+	bp, err := ctx.ImportDir(dir, 0)
 	if err != nil {
+		var noGo *build.NoGoError
+		if errors.As(err, &noGo) {
+			return nil, ErrPackageNotFound
+		}
 		return nil, err
 	}
 
+	if len(bp.GoFiles)+len(bp.CgoFiles) == 0 {
+		return nil, ErrPackageNotFound
+	}
+
+	goFiles := make([]string, 0, len(bp.GoFiles)+len(bp.CgoFiles))
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		goFiles = append(goFiles, filepath.Join(dir, name))
+	}
+	sort.Strings(goFiles)
+
 	return &packages.Package{
-		Name:    f.Name.Name,
+		Name:    bp.Name,
 		PkgPath: importPath,
 		GoFiles: goFiles,
 	}, nil
 }
 
-// AST returns package's abstract syntax tree
+// AST returns package's abstract syntax tree built from the Go files of the package,
+// which only lists the files matching the build constraints. Files of the external
+// _test package are skipped; a file of any other package returns a *build.MultiplePackageError.
 func AST(fs *token.FileSet, p *packages.Package) (*Package, error) {
-	dir := Dir(p)
-
-	pkgs, err := parser.ParseDir(fs, dir, nil, parser.DeclarationErrors|parser.ParseComments)
-	if err != nil {
-		return nil, err
-	}
+	files := make(map[string]*ast.File, len(p.GoFiles))
+	name, nameFile := p.Name, ""
+	for _, filename := range p.GoFiles {
+		f, err := parser.ParseFile(fs, filename, nil, parser.DeclarationErrors|parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
 
-	if ap, ok := pkgs[p.Name]; ok {
-		return &Package{
-			Name:  p.Name,
-			Files: ap.Files,
-		}, nil
+		switch pkgName := f.Name.Name; {
+		case name == "":
+			name, nameFile = pkgName, filename
+		case pkgName == name:
+			if nameFile == "" {
+				nameFile = filename
+			}
+		case strings.HasSuffix(filename, "_test.go") && pkgName == name+"_test":
+			continue
+		default:
+			return nil, &build.MultiplePackageError{
+				Dir:      filepath.Dir(filename),
+				Packages: []string{name, pkgName},
+				Files:    []string{filepath.Base(nameFile), filepath.Base(filename)},
+			}
+		}
+		files[filename] = f
 	}
 
-	return &Package{Name: p.Name}, nil
+	return &Package{
+		Name:  name,
+		Files: files,
+	}, nil
 }
 
 // Dir returns absolute path of the package in a filesystem
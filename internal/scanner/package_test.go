@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"go/build"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestBuildFromDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		tags      []string
+		wantName  string
+		wantFiles []string
+		wantErr   string
+	}{
+		{
+			name: "external test package",
+			files: map[string]string{
+				"svc.go":      "package svc\n",
+				"svc_test.go": "package svc_test\n",
+			},
+			wantName:  "svc",
+			wantFiles: []string{"svc.go"},
+		},
+		{
+			name: "external test package first",
+			files: map[string]string{
+				"a_test.go": "package svc_test\n",
+				"b.go":      "package svc\n",
+			},
+			wantName:  "svc",
+			wantFiles: []string{"b.go"},
+		},
+		{
+			name: "ignored main",
+			files: map[string]string{
+				"gen.go": "//go:build ignore\n\npackage main\n",
+				"svc.go": "package svc\n",
+			},
+			wantName:  "svc",
+			wantFiles: []string{"svc.go"},
+		},
+		{
+			name: "documentation package",
+			files: map[string]string{
+				"doc.go": "package documentation\n",
+				"svc.go": "package svc\n",
+			},
+			wantName:  "svc",
+			wantFiles: []string{"svc.go"},
+		},
+		{
+			name: "build tags",
+			files: map[string]string{
+				"svc.go":         "package svc\n",
+				"integration.go": "//go:build integration\n\npackage svc\n",
+			},
+			tags:      []string{"integration"},
+			wantName:  "svc",
+			wantFiles: []string{"integration.go", "svc.go"},
+		},
+		{
+			name: "conflicting packages",
+			files: map[string]string{
+				"a.go": "package svc\n",
+				"b.go": "package other\n",
+			},
+			wantErr: "found packages svc (a.go) and other (b.go)",
+		},
+		{
+			name: "only tests",
+			files: map[string]string{
+				"svc_test.go": "package svc_test\n",
+			},
+			wantErr: ErrPackageNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+
+			p, err := BuildFromDir("example.com/svc", dir, tt.tags)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, p.Name)
+
+			var files []string
+			for _, f := range p.GoFiles {
+				files = append(files, filepath.Base(f))
+			}
+			assert.Equal(t, tt.wantFiles, files)
+		})
+	}
+}
+
+func TestAST_ConflictingPackages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":      "package svc\n",
+		"a_test.go": "package svc_test\n",
+		"b.go":      "package other\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	p := &packages.Package{Name: "svc", GoFiles: []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "a_test.go")}}
+	astPkg, err := AST(token.NewFileSet(), p)
+	require.NoError(t, err)
+	assert.Len(t, astPkg.Files, 1)
+
+	p.GoFiles = append(p.GoFiles, filepath.Join(dir, "b.go"))
+	_, err = AST(token.NewFileSet(), p)
+	var multiple *build.MultiplePackageError
+	require.ErrorAs(t, err, &multiple)
+	assert.Equal(t, []string{"svc", "other"}, multiple.Packages)
+	assert.Equal(t, []string{"a.go", "b.go"}, multiple.Files)
+}
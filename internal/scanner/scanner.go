@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"path/filepath"
@@ -8,23 +9,75 @@ import (
 	"strings"
 )
 
-const ignoreDirective = "//ddtrace:ignore"
-
 // InterfaceInfo represents a discovered interface in a source file.
 type InterfaceInfo struct {
 	Name string
+
+	// Ignored reports whether the interface is annotated with //ddtrace:ignore.
+	// Only ScanPackageAll returns ignored interfaces.
+	Ignored bool
+
+	// Annotated reports whether the interface is annotated with //ddtrace:trace.
+	Annotated bool
+
+	// Methods lists the methods declared directly in the interface, in source order.
+	// Methods of embedded interfaces are not included.
+	Methods []MethodInfo
+
+	// Embeds is the number of embedded interfaces and type elements.
+	Embeds int
+
+	// Span holds the span directives of the interface's doc comment.
+	Span SpanDirectives
+}
+
+// MethodInfo represents a method declared in a discovered interface.
+type MethodInfo struct {
+	Name string
+
+	// AcceptsContext reports whether the first parameter is a context.Context.
+	AcceptsContext bool
+}
+
+// HasContextMethods reports whether any declared method accepts a context.Context.
+func (i InterfaceInfo) HasContextMethods() bool {
+	for _, m := range i.Methods {
+		if m.AcceptsContext {
+			return true
+		}
+	}
+	return false
 }
 
 // FileInterfaces represents all non-ignored interfaces found in a single source file.
 type FileInterfaces struct {
 	FileName   string
 	Interfaces []InterfaceInfo
+
+	// Constraint is the build constraint of the file, combining its //go:build line
+	// and the GOOS and GOARCH of its name, in //go:build syntax, or "" if it has none.
+	Constraint string
+
+	// NameConstraint is the part of Constraint implied by the GOOS and GOARCH of the
+	// file name, or "" if its name has no such suffix.
+	NameConstraint string
 }
 
 // ScanPackage scans all files in a package and returns interfaces grouped by file.
-// Interfaces annotated with //ddtrace:ignore are excluded.
+// Interfaces annotated with //ddtrace:ignore are excluded. Invalid //ddtrace:
+// directives in the doc comments of interfaces are errors.
 // Files ending in _test.go or _trace.go are skipped.
 func ScanPackage(p *Package) ([]FileInterfaces, error) {
+	return scanPackage(p, false)
+}
+
+// ScanPackageAll is like ScanPackage but also returns interfaces annotated with
+// //ddtrace:ignore, with Ignored set.
+func ScanPackageAll(p *Package) ([]FileInterfaces, error) {
+	return scanPackage(p, true)
+}
+
+func scanPackage(p *Package, includeIgnored bool) ([]FileInterfaces, error) {
 	var result []FileInterfaces
 
 	fileNames := make([]string, 0, len(p.Files))
@@ -45,21 +98,31 @@ func ScanPackage(p *Package) ([]FileInterfaces, error) {
 			continue
 		}
 
-		interfaces := scanFile(f)
+		interfaces, err := scanFile(f, includeIgnored)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", baseName, err)
+		}
 		if len(interfaces) == 0 {
 			continue
 		}
 
-		result = append(result, FileInterfaces{
+		fi := FileInterfaces{
 			FileName:   baseName,
 			Interfaces: interfaces,
-		})
+		}
+		if expr := fileConstraint(baseName, f); expr != nil {
+			fi.Constraint = expr.String()
+		}
+		if expr := nameExpr(baseName); expr != nil {
+			fi.NameConstraint = expr.String()
+		}
+		result = append(result, fi)
 	}
 
 	return result, nil
 }
 
-func scanFile(f *ast.File) []InterfaceInfo {
+func scanFile(f *ast.File, includeIgnored bool) ([]InterfaceInfo, error) {
 	var interfaces []InterfaceInfo
 
 	for _, decl := range f.Decls {
@@ -74,31 +137,65 @@ func scanFile(f *ast.File) []InterfaceInfo {
 				continue
 			}
 
-			if _, isIface := ts.Type.(*ast.InterfaceType); !isIface {
+			it, isIface := ts.Type.(*ast.InterfaceType)
+			if !isIface {
 				continue
 			}
 
-			if hasIgnoreDirective(gd.Doc) || hasIgnoreDirective(ts.Doc) {
+			directives, err := ParseDirectives(false, gd.Doc, ts.Doc)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: %w", ts.Name.Name, err)
+			}
+			if directives.Ignore && !includeIgnored {
 				continue
 			}
 
 			interfaces = append(interfaces, InterfaceInfo{
-				Name: ts.Name.Name,
+				Name:      ts.Name.Name,
+				Ignored:   directives.Ignore,
+				Annotated: directives.Trace,
+				Methods:   scanMethods(it),
+				Embeds:    countEmbeds(it),
+				Span:      directives.Span,
 			})
 		}
 	}
 
-	return interfaces
+	return interfaces, nil
 }
 
-func hasIgnoreDirective(cg *ast.CommentGroup) bool {
-	if cg == nil {
-		return false
+func scanMethods(it *ast.InterfaceType) []MethodInfo {
+	var methods []MethodInfo
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			continue
+		}
+		for _, name := range field.Names {
+			methods = append(methods, MethodInfo{
+				Name:           name.Name,
+				AcceptsContext: acceptsContext(ft),
+			})
+		}
 	}
-	for _, c := range cg.List {
-		if strings.TrimSpace(c.Text) == ignoreDirective {
-			return true
+	return methods
+}
+
+func countEmbeds(it *ast.InterfaceType) int {
+	n := 0
+	for _, field := range it.Methods.List {
+		if _, ok := field.Type.(*ast.FuncType); !ok {
+			n++
 		}
 	}
-	return false
+	return n
+}
+
+// acceptsContext mirrors codegen.NewMethod: the first parameter must be a selector named Context.
+func acceptsContext(ft *ast.FuncType) bool {
+	if ft.Params == nil || len(ft.Params.List) == 0 {
+		return false
+	}
+	se, ok := ft.Params.List[0].Type.(*ast.SelectorExpr)
+	return ok && se.Sel.Name == "Context"
 }
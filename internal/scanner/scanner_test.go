@@ -305,3 +305,94 @@ func TestScanPackage_EmptyPackage(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, result)
 }
+
+func TestScanPackage_Methods(t *testing.T) {
+	p := parseSource(t, "service.go", `
+package testpkg
+
+import (
+	"context"
+	"io"
+)
+
+type UserService interface {
+	io.Closer
+	GetUser(ctx context.Context, id string) error
+	Name() string
+}
+
+type Plain interface {
+	Name() string
+}
+`)
+
+	result, err := ScanPackage(p)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Interfaces, 2)
+
+	userService := result[0].Interfaces[0]
+	assert.Equal(t, []MethodInfo{
+		{Name: "GetUser", AcceptsContext: true},
+		{Name: "Name"},
+	}, userService.Methods)
+	assert.True(t, userService.HasContextMethods())
+	assert.False(t, result[0].Interfaces[1].HasContextMethods())
+}
+
+func TestScanPackageAll_IncludesIgnored(t *testing.T) {
+	p := parseSource(t, "service.go", `
+package testpkg
+
+import "context"
+
+type UserService interface {
+	GetUser(ctx context.Context, id string) error
+}
+
+//ddtrace:ignore
+type InternalHelper interface {
+	DoSomething(ctx context.Context) error
+}
+`)
+
+	result, err := ScanPackageAll(p)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Interfaces, 2)
+	assert.False(t, result[0].Interfaces[0].Ignored)
+	assert.Equal(t, "InternalHelper", result[0].Interfaces[1].Name)
+	assert.True(t, result[0].Interfaces[1].Ignored)
+}
+
+func TestScanPackage_Constraint(t *testing.T) {
+	tests := []struct {
+		filename string
+		src      string
+		want     string
+		wantName string
+	}{
+		{filename: "service.go", src: "package testpkg\n", want: ""},
+		{filename: "service_linux.go", src: "package testpkg\n", want: "linux", wantName: "linux"},
+		{filename: "service_linux_arm64.go", src: "package testpkg\n", want: "linux && arm64", wantName: "linux && arm64"},
+		{filename: "service_amd64.go", src: "package testpkg\n", want: "amd64", wantName: "amd64"},
+		{filename: "linux.go", src: "package testpkg\n", want: ""},
+		{filename: "service_unknown.go", src: "package testpkg\n", want: ""},
+		{filename: "service.go", src: "//go:build integration || e2e\n\npackage testpkg\n", want: "integration || e2e"},
+		{filename: "service_windows.go", src: "//go:build !race\n\npackage testpkg\n", want: "!race && windows", wantName: "windows"},
+		{filename: "service_linux_arm64.go", src: "//go:build cgo\n\npackage testpkg\n", want: "cgo && linux && arm64", wantName: "linux && arm64"},
+		{filename: "service.go", src: "package testpkg\n\n//go:build ignored\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename+" "+tt.want, func(t *testing.T) {
+			p := parseSource(t, tt.filename, tt.src+"\ntype Service interface {\n\tDo()\n}\n")
+
+			result, err := ScanPackage(p)
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+			assert.Equal(t, tt.want, result[0].Constraint)
+			assert.Equal(t, tt.wantName, result[0].NameConstraint)
+		})
+	}
+}
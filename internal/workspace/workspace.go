@@ -0,0 +1,361 @@
+// Package workspace maps import paths to directories the way the go command does:
+// across the modules of a go.work workspace, following replace directives and
+// loading dependencies from the vendor directory in -mod=vendor mode.
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Module is a main module of a workspace.
+type Module struct {
+	Path string
+	Dir  string
+}
+
+// PackageDir returns the directory of importPath, which must be a package of m.
+func (m Module) PackageDir(importPath string) string {
+	return filepath.Join(m.Dir, filepath.FromSlash(strings.TrimPrefix(importPath, m.Path)))
+}
+
+// Workspace is the set of main modules packages are loaded from and the modules they require.
+type Workspace struct {
+	// Root is the directory of go.work, or of go.mod outside of workspace mode.
+	Root string
+
+	// Modules are the modules used by go.work, or the module containing the start
+	// directory, longest path first.
+	Modules []Module
+
+	// Vendor is the vendor directory dependencies are loaded from in -mod=vendor mode, or "".
+	Vendor string
+
+	// replaced maps modules replaced by a local path to their absolute directory.
+	replaced map[string]string
+
+	// versions maps required modules to the module@version they are built from.
+	versions map[string]string
+}
+
+// Load returns the workspace of startDir: the go.work file selected by GOWORK or found
+// in startDir or a parent directory, or else the module whose go.mod is nearest to startDir.
+func Load(startDir string) (*Workspace, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{replaced: map[string]string{}, versions: map[string]string{}}
+
+	workFile, err := findWorkFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	if workFile != "" {
+		if err := ws.loadWork(workFile); err != nil {
+			return nil, err
+		}
+	} else {
+		root := findUp(dir, "go.mod")
+		if root == "" {
+			return nil, errors.New("go.mod not found")
+		}
+		ws.Root = root
+		f, err := ws.loadModule(root)
+		if err != nil {
+			return nil, err
+		}
+		ws.applyReplaces(root, f.Replace)
+		ws.setVendor(f.Go)
+	}
+
+	sort.Slice(ws.Modules, func(i, j int) bool { return len(ws.Modules[i].Path) > len(ws.Modules[j].Path) })
+	return ws, nil
+}
+
+// findWorkFile returns the go.work file in effect for dir, or "" outside of workspace mode.
+func findWorkFile(dir string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return "", nil
+	case "":
+		if root := findUp(dir, "go.work"); root != "" {
+			return filepath.Join(root, "go.work"), nil
+		}
+		return "", nil
+	default:
+		if !filepath.IsAbs(gowork) {
+			return "", errors.Errorf("GOWORK must be an absolute path, got %q", gowork)
+		}
+		return gowork, nil
+	}
+}
+
+// findUp returns the nearest of dir and its parents containing name, or "".
+func findUp(dir, name string) string {
+	for {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func (ws *Workspace) loadWork(workFile string) error {
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read go.work")
+	}
+	wf, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse go.work")
+	}
+
+	ws.Root = filepath.Dir(workFile)
+	for _, use := range wf.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(ws.Root, dir)
+		}
+		f, err := ws.loadModule(dir)
+		if err != nil {
+			return err
+		}
+		ws.applyReplaces(dir, f.Replace)
+	}
+
+	// Replacements of go.work override those of the modules.
+	ws.applyReplaces(ws.Root, wf.Replace)
+	ws.setVendor(wf.Go)
+	return nil
+}
+
+// loadModule adds the module in dir to the main modules and records its requirements,
+// keeping the highest version required by any main module.
+func (ws *Workspace) loadModule(dir string) (*modfile.File, error) {
+	gomod := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read go.mod")
+	}
+	f, err := modfile.Parse(gomod, data, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", gomod)
+	}
+	if f.Module == nil {
+		return nil, errors.Errorf("%s has no module directive", gomod)
+	}
+
+	ws.Modules = append(ws.Modules, Module{Path: f.Module.Mod.Path, Dir: dir})
+	for _, r := range f.Require {
+		if prev, ok := ws.versions[r.Mod.Path]; ok {
+			_, prevVersion, _ := strings.Cut(prev, "@")
+			if semver.Compare(prevVersion, r.Mod.Version) >= 0 {
+				continue
+			}
+		}
+		ws.versions[r.Mod.Path] = r.Mod.String()
+	}
+	return f, nil
+}
+
+// applyReplaces applies replace directives of a go.mod or go.work in dir.
+func (ws *Workspace) applyReplaces(dir string, replaces []*modfile.Replace) {
+	for _, r := range replaces {
+		if r.New.Version != "" {
+			ws.versions[r.Old.Path] = r.New.String()
+			delete(ws.replaced, r.Old.Path)
+			continue
+		}
+		target := filepath.FromSlash(r.New.Path)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		ws.replaced[r.Old.Path] = target
+		delete(ws.versions, r.Old.Path)
+	}
+}
+
+// setVendor enables vendor mode like the go command: with -mod=vendor in GOFLAGS, or by
+// default when the root has a vendor/modules.txt and declares go 1.14 or later.
+func (ws *Workspace) setVendor(goVersion *modfile.Go) {
+	vendor := filepath.Join(ws.Root, "vendor")
+
+	for _, flag := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if mode, ok := strings.CutPrefix(strings.TrimLeft(flag, "-"), "mod="); ok {
+			if mode == "vendor" {
+				ws.Vendor = vendor
+			}
+			return
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(vendor, "modules.txt")); err != nil {
+		return
+	}
+	if goVersion != nil && semver.Compare("v"+goVersion.Version, "v1.14") >= 0 {
+		ws.Vendor = vendor
+	}
+}
+
+// Module returns the main module providing importPath.
+func (ws *Workspace) Module(importPath string) (Module, bool) {
+	for _, m := range ws.Modules {
+		if inModule(m.Path, importPath) {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// Dir returns the directory importPath is loaded from: a directory of a main module,
+// of the vendor directory, or of a module replaced by a local path. It returns ""
+// for packages of the module cache and the standard library.
+func (ws *Workspace) Dir(importPath string) string {
+	if m, ok := ws.Module(importPath); ok {
+		return m.PackageDir(importPath)
+	}
+
+	if ws.Vendor != "" {
+		dir := filepath.Join(ws.Vendor, filepath.FromSlash(importPath))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		return ""
+	}
+
+	if mod := longestModule(ws.replaced, importPath); mod != "" {
+		return filepath.Join(ws.replaced[mod], filepath.FromSlash(strings.TrimPrefix(importPath, mod)))
+	}
+	return ""
+}
+
+// Version returns the module@version importPath is built from, or "" if no required
+// module provides it.
+func (ws *Workspace) Version(importPath string) string {
+	if mod := longestModule(ws.versions, importPath); mod != "" {
+		return ws.versions[mod]
+	}
+	return ""
+}
+
+// ExpandPattern returns the packages of the main modules matching a "/..." pattern,
+// sorted, by walking the filesystem instead of using `go list`. Nested modules that
+// aren't main modules of the workspace, vendor and testdata directories are skipped.
+func (ws *Workspace) ExpandPattern(pattern string) ([]string, error) {
+	var paths []string
+	err := ws.walkPattern(pattern, func(m Module, dir string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") && !strings.HasSuffix(e.Name(), "_test.go") {
+				importPath := m.Path
+				if rel, _ := filepath.Rel(m.Dir, dir); rel != "." {
+					importPath += "/" + filepath.ToSlash(rel)
+				}
+				paths = append(paths, importPath)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// PatternDirs returns the directories ExpandPattern walks for a "/..." pattern, sorted,
+// whether or not they hold a package yet.
+func (ws *Workspace) PatternDirs(pattern string) ([]string, error) {
+	var dirs []string
+	err := ws.walkPattern(pattern, func(_ Module, dir string) {
+		dirs = append(dirs, dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// walkPattern calls fn with every directory of the main modules a "/..." pattern matches.
+func (ws *Workspace) walkPattern(pattern string, fn func(m Module, dir string)) error {
+	prefix := strings.TrimSuffix(pattern, "/...")
+
+	matched := false
+	for _, m := range ws.Modules {
+		var searchDir string
+		switch {
+		case inModule(m.Path, prefix):
+			searchDir = m.PackageDir(prefix)
+		case inModule(prefix, m.Path):
+			searchDir = m.Dir
+		default:
+			continue
+		}
+		matched = true
+
+		if err := walkDirs(m, searchDir, fn); err != nil {
+			return err
+		}
+	}
+	if !matched {
+		return errors.Errorf("pattern %q is outside the main modules", pattern)
+	}
+	return nil
+}
+
+// walkDirs calls fn with searchDir and its subdirectories belonging to module m.
+func walkDirs(m Module, searchDir string, fn func(m Module, dir string)) error {
+	err := filepath.WalkDir(searchDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip inaccessible directories
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != m.Dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		if path != m.Dir {
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		fn(m, path)
+		return nil
+	})
+	return errors.Wrapf(err, "failed to walk %s", searchDir)
+}
+
+// longestModule returns the longest module path of mods providing importPath.
+func longestModule(mods map[string]string, importPath string) string {
+	longest := ""
+	for mod := range mods {
+		if inModule(mod, importPath) && len(mod) > len(longest) {
+			longest = mod
+		}
+	}
+	return longest
+}
+
+func inModule(modulePath, importPath string) bool {
+	return importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+}
@@ -0,0 +1,216 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	type lookup struct {
+		importPath string
+		dir        string // relative to the temporary directory, "" if none
+		version    string
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		start   string
+		env     map[string]string
+		modules []string
+		vendor  bool
+		lookups []lookup
+	}{
+		{
+			name: "module",
+			files: map[string]string{
+				"app/go.mod":     "module example.com/app\n\ngo 1.23\n\nrequire (\n\texample.com/lib v1.2.0\n\texample.com/local v0.0.0\n)\n\nreplace example.com/local => ../local\n",
+				"app/svc/svc.go": "package svc\n",
+				"local/go.mod":   "module example.com/local\n",
+				"local/pkg/a.go": "package pkg\n",
+			},
+			start:   "app/svc",
+			modules: []string{"example.com/app"},
+			lookups: []lookup{
+				{importPath: "example.com/app/svc", dir: "app/svc"},
+				{importPath: "example.com/local/pkg", dir: "local/pkg"},
+				{importPath: "example.com/lib/sub", version: "example.com/lib@v1.2.0"},
+				{importPath: "context"},
+			},
+		},
+		{
+			name: "workspace",
+			files: map[string]string{
+				"go.work":            "go 1.23\n\nuse (\n\t./app\n\t./app/tools\n\t./shared\n)\n\nreplace example.com/lib v1.2.0 => example.com/lib v1.3.0\n",
+				"app/go.mod":         "module example.com/app\n\ngo 1.23\n\nrequire example.com/lib v1.2.0\n",
+				"app/tools/go.mod":   "module example.com/app/tools\n\ngo 1.23\n\nrequire example.com/lib v1.1.0\n",
+				"shared/go.mod":      "module example.com/shared\n\ngo 1.23\n",
+				"shared/model/m.go":  "package model\n",
+				"app/tools/gen/g.go": "package gen\n",
+			},
+			start:   "app",
+			modules: []string{"example.com/app/tools", "example.com/shared", "example.com/app"},
+			lookups: []lookup{
+				{importPath: "example.com/app/tools/gen", dir: "app/tools/gen"},
+				{importPath: "example.com/shared/model", dir: "shared/model"},
+				{importPath: "example.com/lib", version: "example.com/lib@v1.3.0"},
+			},
+		},
+		{
+			name: "workspace off",
+			files: map[string]string{
+				"go.work":       "go 1.23\n\nuse ./app\n",
+				"app/go.mod":    "module example.com/app\n\ngo 1.23\n",
+				"shared/go.mod": "module example.com/shared\n",
+			},
+			start:   "app",
+			env:     map[string]string{"GOWORK": "off"},
+			modules: []string{"example.com/app"},
+			lookups: []lookup{
+				{importPath: "example.com/shared/model"},
+			},
+		},
+		{
+			name: "vendor",
+			files: map[string]string{
+				"go.mod":                            "module example.com/app\n\ngo 1.23\n\nrequire example.com/local v0.0.0\n\nreplace example.com/local => ../local\n",
+				"vendor/modules.txt":                "# example.com/local v0.0.0 => ../local\n## explicit\nexample.com/local/pkg\n",
+				"vendor/example.com/local/pkg/a.go": "package pkg\n",
+			},
+			start:   ".",
+			modules: []string{"example.com/app"},
+			vendor:  true,
+			lookups: []lookup{
+				{importPath: "example.com/local/pkg", dir: "vendor/example.com/local/pkg"},
+				{importPath: "example.com/other"},
+			},
+		},
+		{
+			name: "vendor disabled by GOFLAGS",
+			files: map[string]string{
+				"go.mod":             "module example.com/app\n\ngo 1.23\n\nrequire example.com/local v0.0.0\n\nreplace example.com/local => ./local\n",
+				"vendor/modules.txt": "# example.com/local v0.0.0 => ./local\n",
+			},
+			start:   ".",
+			env:     map[string]string{"GOFLAGS": "-mod=mod"},
+			modules: []string{"example.com/app"},
+			lookups: []lookup{
+				{importPath: "example.com/local/pkg", dir: "local/pkg"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOWORK", "")
+			t.Setenv("GOFLAGS", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			dir := writeFiles(t, tt.files)
+			ws, err := Load(filepath.Join(dir, tt.start))
+			require.NoError(t, err)
+
+			var modules []string
+			for _, m := range ws.Modules {
+				modules = append(modules, m.Path)
+			}
+			assert.ElementsMatch(t, tt.modules, modules)
+			assert.Equal(t, tt.vendor, ws.Vendor != "")
+
+			for _, l := range tt.lookups {
+				want := ""
+				if l.dir != "" {
+					want = filepath.Join(dir, filepath.FromSlash(l.dir))
+				}
+				assert.Equal(t, want, ws.Dir(l.importPath), "Dir(%s)", l.importPath)
+				assert.Equal(t, l.version, ws.Version(l.importPath), "Version(%s)", l.importPath)
+			}
+		})
+	}
+}
+
+func TestWorkspace_ExpandPattern(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	dir := writeFiles(t, map[string]string{
+		"go.work":               "go 1.23\n\nuse (\n\t./app\n\t./app/tools\n\t./shared\n)\n",
+		"app/go.mod":            "module example.com/app\n\ngo 1.23\n",
+		"app/svc/svc.go":        "package svc\n",
+		"app/svc/svc_test.go":   "package svc\n",
+		"app/vendor/x/x.go":     "package x\n",
+		"app/testdata/t/t.go":   "package t\n",
+		"app/tools/go.mod":      "module example.com/app/tools\n\ngo 1.23\n",
+		"app/tools/gen/gen.go":  "package gen\n",
+		"app/nested/go.mod":     "module example.com/app/nested\n",
+		"app/nested/n/n.go":     "package n\n",
+		"shared/go.mod":         "module example.com/shared\n\ngo 1.23\n",
+		"shared/model/model.go": "package model\n",
+	})
+
+	ws, err := Load(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		pattern string
+		want    []string
+		wantErr string
+	}{
+		{pattern: "example.com/app/...", want: []string{"example.com/app/svc", "example.com/app/tools/gen"}},
+		{pattern: "example.com/app/tools/...", want: []string{"example.com/app/tools/gen"}},
+		{pattern: "example.com/shared/...", want: []string{"example.com/shared/model"}},
+		{pattern: "example.com/...", want: []string{"example.com/app/svc", "example.com/app/tools/gen", "example.com/shared/model"}},
+		{pattern: "example.org/...", wantErr: "outside the main modules"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := ws.ExpandPattern(tt.pattern)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWorkspace_PatternDirs(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	dir := writeFiles(t, map[string]string{
+		"go.mod":              "module example.com/app\n\ngo 1.23\n",
+		"svc/svc.go":          "package svc\n",
+		"svc/internal/doc.md": "",
+		"empty/.keep":         "",
+		"testdata/t/t.go":     "package t\n",
+		"nested/go.mod":       "module example.com/app/nested\n",
+		"nested/n/n.go":       "package n\n",
+	})
+
+	ws, err := Load(dir)
+	require.NoError(t, err)
+
+	got, err := ws.PatternDirs("example.com/app/...")
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir, filepath.Join(dir, "empty"), filepath.Join(dir, "svc"), filepath.Join(dir, "svc", "internal")}, got)
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by circuit breaker decorators while the circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreaker stops calling the wrapped implementation after a number of consecutive
+// failures, rejecting calls with ErrCircuitOpen until the open timeout elapses. After that
+// a single trial call is let through: success closes the circuit, failure opens it again.
+// All methods are safe for concurrent use by multiple goroutines.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+
+	threshold   int
+	openTimeout time.Duration
+	failureIf   func(err error) bool
+	now         func() time.Time
+}
+
+// CircuitBreakerOption configures a CircuitBreaker.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// NewCircuitBreaker creates a CircuitBreaker with the given options.
+// By default the circuit opens after 5 consecutive failures and stays open for 30 seconds.
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		threshold:   5,
+		openTimeout: 30 * time.Second,
+		failureIf:   isRetryable,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// WithFailureThreshold sets the number of consecutive failures that opens the circuit.
+func WithFailureThreshold(n int) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.threshold = n
+	}
+}
+
+// WithOpenTimeout sets how long the circuit stays open before a trial call is allowed.
+func WithOpenTimeout(d time.Duration) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.openTimeout = d
+	}
+}
+
+// WithFailureIf sets the predicate deciding whether an error counts as a failure.
+// By default context cancellation and deadline errors are not counted.
+func WithFailureIf(f func(err error) bool) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.failureIf = f
+	}
+}
+
+// Allow reports whether a call may proceed, returning ErrCircuitOpen if not.
+// Every successful Allow must be followed by Done.
+// This method is called by generated decorator code.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Done records the outcome of a call allowed by Allow. Errors that don't count as
+// failures leave the state unchanged; a trial call ending with one lets the next call
+// be the trial.
+// This method is called by generated decorator code.
+func (b *CircuitBreaker) Done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.state = circuitClosed
+		b.failures = 0
+		return
+	}
+	if !b.failureIf(err) {
+		if b.state == circuitHalfOpen {
+			// openedAt is kept, so the next Allow starts a new trial right away.
+			b.state = circuitOpen
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(WithFailureThreshold(2), WithOpenTimeout(time.Minute))
+	b.now = func() time.Time { return now }
+
+	errBoom := errors.New("boom")
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.Allow())
+		b.Done(errBoom)
+	}
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// after the open timeout a single trial call is allowed
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// a failed trial opens the circuit again
+	b.Done(errBoom)
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// a successful trial closes it
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.Done(nil)
+	assert.NoError(t, b.Allow())
+	b.Done(nil)
+}
+
+func TestCircuitBreaker_IgnoredErrors(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(WithFailureThreshold(2), WithOpenTimeout(time.Minute))
+	b.now = func() time.Time { return now }
+
+	errBoom := errors.New("boom")
+
+	// ignored errors neither count as failures nor reset them
+	assert.NoError(t, b.Allow())
+	b.Done(context.Canceled)
+	assert.NoError(t, b.Allow())
+	b.Done(errBoom)
+	assert.NoError(t, b.Allow())
+	b.Done(context.Canceled)
+	assert.NoError(t, b.Allow())
+	b.Done(errBoom)
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// a trial ending with an ignored error keeps the circuit open and allows a new trial
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.Done(context.DeadlineExceeded)
+	assert.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	b.Done(nil)
+	assert.NoError(t, b.Allow())
+	b.Done(nil)
+
+	b = NewCircuitBreaker(WithFailureThreshold(1), WithFailureIf(func(error) bool { return false }))
+	assert.NoError(t, b.Allow())
+	b.Done(errBoom)
+	assert.NoError(t, b.Allow())
+}
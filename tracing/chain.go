@@ -0,0 +1,58 @@
+package tracing
+
+// ChainConfig collects the per-decorator options passed to generated New<Interface>Chain constructors.
+type ChainConfig struct {
+	Tracing        []TracingOption
+	Logging        []LoggingOption
+	Timeout        []TimeoutOption
+	Retry          []RetryOption
+	CircuitBreaker []CircuitBreakerOption
+}
+
+// ChainOption configures a ChainConfig.
+type ChainOption func(*ChainConfig)
+
+// NewChainConfig creates a ChainConfig with the given options.
+// This function is called by generated decorator code.
+func NewChainConfig(opts ...ChainOption) ChainConfig {
+	cfg := ChainConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// ChainTracing passes options to the tracing decorator of a chain.
+func ChainTracing(opts ...TracingOption) ChainOption {
+	return func(c *ChainConfig) {
+		c.Tracing = append(c.Tracing, opts...)
+	}
+}
+
+// ChainLogging passes options to the logging decorator of a chain.
+func ChainLogging(opts ...LoggingOption) ChainOption {
+	return func(c *ChainConfig) {
+		c.Logging = append(c.Logging, opts...)
+	}
+}
+
+// ChainTimeout passes options to the timeout decorator of a chain.
+func ChainTimeout(opts ...TimeoutOption) ChainOption {
+	return func(c *ChainConfig) {
+		c.Timeout = append(c.Timeout, opts...)
+	}
+}
+
+// ChainRetry passes options to the retry decorator of a chain.
+func ChainRetry(opts ...RetryOption) ChainOption {
+	return func(c *ChainConfig) {
+		c.Retry = append(c.Retry, opts...)
+	}
+}
+
+// ChainCircuitBreaker passes options to the circuit breaker decorator of a chain.
+func ChainCircuitBreaker(opts ...CircuitBreakerOption) ChainOption {
+	return func(c *ChainConfig) {
+		c.CircuitBreaker = append(c.CircuitBreaker, opts...)
+	}
+}
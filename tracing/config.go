@@ -2,6 +2,7 @@ package tracing
 
 import (
 	"context"
+	"time"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -12,6 +13,9 @@ type TracingConfig struct {
 	spanDecorator    func(span ddtrace.Span, params, results map[string]interface{})
 	contextDecorator func(ctx context.Context, span ddtrace.Span)
 	spanOpts         []tracer.StartSpanOption
+	requireParent    bool
+	metrics          bool
+	metricsSink      MetricsSink
 }
 
 // TracingOption configures a TracingConfig.
@@ -20,12 +24,15 @@ type TracingOption func(*TracingConfig)
 // NewTracingConfig creates a TracingConfig with the given options.
 // Global span options are automatically prepended; per-instance options take precedence.
 func NewTracingConfig(opts ...TracingOption) TracingConfig {
-	cfg := TracingConfig{}
+	cfg := TracingConfig{requireParent: globalRequireParent}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 	// Prepend global span options; per-instance options take precedence
 	cfg.spanOpts = append(append([]tracer.StartSpanOption{}, globalSpanOpts...), cfg.spanOpts...)
+	if cfg.metrics && cfg.metricsSink == nil {
+		cfg.metricsSink = globalMetricsSink
+	}
 	return cfg
 }
 
@@ -53,11 +60,68 @@ func WithSpanOptions(opts ...tracer.StartSpanOption) TracingOption {
 	}
 }
 
+// WithRequireParent makes the decorator skip span creation when the context has no
+// active parent span, so calls made outside of a trace do not produce orphan root spans.
+func WithRequireParent() TracingOption {
+	return func(c *TracingConfig) {
+		c.requireParent = true
+	}
+}
+
+// WithoutRequireParent makes the decorator create spans whether or not the context has
+// an active parent span, overriding SetDefaultRequireParent(true).
+func WithoutRequireParent() TracingOption {
+	return func(c *TracingConfig) {
+		c.requireParent = false
+	}
+}
+
+// WithMetrics enables call count, error count and duration metrics for every traced method,
+// reported to the sink set by SetDefaultMetricsSink.
+func WithMetrics() TracingOption {
+	return func(c *TracingConfig) {
+		c.metrics = true
+	}
+}
+
+// WithMetricsSink enables metrics for every traced method and reports them to sink
+// instead of the global default.
+func WithMetricsSink(sink MetricsSink) TracingOption {
+	return func(c *TracingConfig) {
+		c.metrics = true
+		c.metricsSink = sink
+	}
+}
+
 // StartSpan creates a new span using this config's span options and context decorators.
 // Global defaults (globalSpanOpts, globalContextDecorator) are included via NewTracingConfig.
+// If the operation is disabled (see Disable), or the config requires a parent and ctx has none,
+// a no-op span and the original ctx are returned. Calls of disabled operations aren't metered.
+// opts are applied after the config's span options, so generated code can set the
+// resource, service, type and tags declared with //ddtrace: directives.
 // This method is called by generated decorator code.
-func (c *TracingConfig) StartSpan(ctx context.Context, operationName string) (ddtrace.Span, context.Context) {
-	span, ctx := tracer.StartSpanFromContext(ctx, operationName, c.spanOpts...)
+func (c *TracingConfig) StartSpan(ctx context.Context, operationName string, opts ...tracer.StartSpanOption) (ddtrace.Span, context.Context) {
+	if IsDisabled(operationName) {
+		return noopSpan, ctx
+	}
+	span, ctx := c.startSpan(ctx, operationName, opts)
+	if c.metricsSink != nil {
+		return &meteredSpan{Span: span, operation: operationName, start: time.Now()}, ctx
+	}
+	return span, ctx
+}
+
+func (c *TracingConfig) startSpan(ctx context.Context, operationName string, opts []tracer.StartSpanOption) (ddtrace.Span, context.Context) {
+	if c.requireParent {
+		if noop, ok := tracer.SpanFromContext(ctx); !ok {
+			return noop, ctx
+		}
+	}
+	spanOpts := c.spanOpts
+	if len(opts) > 0 {
+		spanOpts = append(append([]tracer.StartSpanOption{}, c.spanOpts...), opts...)
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, operationName, spanOpts...)
 	if globalContextDecorator != nil {
 		globalContextDecorator(ctx, span)
 	}
@@ -69,8 +133,13 @@ func (c *TracingConfig) StartSpan(ctx context.Context, operationName string) (dd
 
 // FinishSpan finishes a span. If a spanDecorator is set, it is called with params and results.
 // Otherwise, if err is not nil, error tags are automatically set on the span.
+// If metrics are enabled, the call is also recorded in the metrics sink.
 // This method is called by generated decorator code.
 func (c *TracingConfig) FinishSpan(span ddtrace.Span, err error, params, results map[string]interface{}) {
+	if ms, ok := span.(*meteredSpan); ok {
+		c.metricsSink.Observe(ms.operation, time.Since(ms.start), err)
+		span = ms.Span
+	}
 	if c.spanDecorator != nil {
 		c.spanDecorator(span, params, results)
 	} else if err != nil {
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func TestTracingConfig_StartSpan_RequireParent(t *testing.T) {
+	tests := []struct {
+		name      string
+		withRoot  bool
+		wantSpans int
+	}{
+		{name: "no parent", withRoot: false, wantSpans: 0},
+		{name: "with parent", withRoot: true, wantSpans: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			ctx := context.Background()
+			var root tracer.Span
+			if tt.withRoot {
+				root, ctx = tracer.StartSpanFromContext(ctx, "root")
+			}
+
+			cfg := NewTracingConfig(WithRequireParent())
+			span, spanCtx := cfg.StartSpan(ctx, "Repo.Get")
+			cfg.FinishSpan(span, nil, nil, nil)
+			if root != nil {
+				root.Finish()
+			}
+
+			if !tt.withRoot {
+				assert.Equal(t, ctx, spanCtx)
+			}
+			assert.Len(t, mt.FinishedSpans(), tt.wantSpans)
+		})
+	}
+}
+
+func TestTracingConfig_StartSpan_NoRequireParent(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	cfg := NewTracingConfig()
+	span, _ := cfg.StartSpan(context.Background(), "Repo.Get")
+	cfg.FinishSpan(span, nil, nil, nil)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "Repo.Get", spans[0].OperationName())
+}
+
+func TestSetDefaultRequireParent(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	SetDefaultRequireParent(true)
+	defer SetDefaultRequireParent(false)
+
+	cfg := NewTracingConfig()
+	span, _ := cfg.StartSpan(context.Background(), "Repo.Get")
+	cfg.FinishSpan(span, nil, nil, nil)
+
+	span, _ = StartSpan(context.Background(), WithOperationName("manual"))
+	FinishSpan(span, nil)
+
+	assert.Empty(t, mt.FinishedSpans())
+}
+
+func TestWithoutRequireParent_OverridesDefault(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	SetDefaultRequireParent(true)
+	defer SetDefaultRequireParent(false)
+
+	cfg := NewTracingConfig(WithoutRequireParent())
+	span, _ := cfg.StartSpan(context.Background(), "Repo.Get")
+	cfg.FinishSpan(span, nil, nil, nil)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "Repo.Get", spans[0].OperationName())
+}
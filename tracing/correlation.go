@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Log attribute keys used for Datadog log and trace correlation.
+const (
+	LogKeyTraceID = "dd.trace_id"
+	LogKeySpanID  = "dd.span_id"
+	LogKeyService = "dd.service"
+	LogKeyEnv     = "dd.env"
+	LogKeyVersion = "dd.version"
+)
+
+// unifiedServiceTags returns dd.service, dd.env and dd.version attributes read once from
+// the DD_SERVICE, DD_ENV and DD_VERSION environment variables, the same source the tracer uses.
+var unifiedServiceTags = sync.OnceValue(func() []slog.Attr {
+	var attrs []slog.Attr
+	for _, kv := range [][2]string{
+		{LogKeyService, "DD_SERVICE"},
+		{LogKeyEnv, "DD_ENV"},
+		{LogKeyVersion, "DD_VERSION"},
+	} {
+		if v := os.Getenv(kv[1]); v != "" {
+			attrs = append(attrs, slog.String(kv[0], v))
+		}
+	}
+	return attrs
+})
+
+// LogAttrs returns the attributes correlating a log record with the span in ctx:
+// dd.trace_id and dd.span_id (only if ctx has an active span), plus dd.service, dd.env
+// and dd.version when set in the environment.
+//
+// Example:
+//
+//	logger.LogAttrs(ctx, slog.LevelInfo, "user created", tracing.LogAttrs(ctx)...)
+func LogAttrs(ctx context.Context) []slog.Attr {
+	tags := unifiedServiceTags()
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return append([]slog.Attr(nil), tags...)
+	}
+	attrs := make([]slog.Attr, 0, len(tags)+2)
+	attrs = append(attrs,
+		slog.String(LogKeyTraceID, strconv.FormatUint(span.Context().TraceID(), 10)),
+		slog.String(LogKeySpanID, strconv.FormatUint(span.Context().SpanID(), 10)),
+	)
+	return append(attrs, tags...)
+}
+
+// logHandler is a slog.Handler adding LogAttrs to every record.
+type logHandler struct {
+	slog.Handler
+
+	// root is the wrapped handler before the first group was opened and groups are
+	// the groups opened since with their attributes, so records carrying correlation
+	// attributes are nested in them by Handle while the correlation attributes stay at
+	// the top level where Datadog looks for them.
+	root   slog.Handler
+	groups []logGroup
+}
+
+// logGroup is a group opened with WithGroup and the attributes added to it.
+type logGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// NewLogHandler wraps h so that every record logged with a context (e.g. via
+// slog.InfoContext) carries the correlation attributes returned by LogAttrs.
+//
+// Example:
+//
+//	slog.SetDefault(slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil))))
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return &logHandler{Handler: h}
+}
+
+// Handle implements slog.Handler.
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := LogAttrs(ctx)
+	switch {
+	case len(attrs) == 0:
+		return h.Handler.Handle(ctx, r)
+	case len(h.groups) == 0:
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+		return h.Handler.Handle(ctx, r)
+	}
+
+	nested := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		nested = append(nested, a)
+		return true
+	})
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		nested = []slog.Attr{{Key: g.name, Value: slog.GroupValue(append(slices.Clip(g.attrs), nested...)...)}}
+	}
+
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	out.AddAttrs(attrs...)
+	out.AddAttrs(nested...)
+	return h.root.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(h.groups) == 0 {
+		return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+	}
+
+	groups := slices.Clone(h.groups)
+	last := &groups[len(groups)-1]
+	last.attrs = append(slices.Clip(last.attrs), attrs...)
+	return &logHandler{Handler: h.Handler.WithAttrs(attrs), root: h.root, groups: groups}
+}
+
+// WithGroup implements slog.Handler.
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	root := h.root
+	if len(h.groups) == 0 {
+		root = h.Handler
+	}
+	return &logHandler{Handler: h.Handler.WithGroup(name), root: root, groups: append(slices.Clip(h.groups), logGroup{name: name})}
+}
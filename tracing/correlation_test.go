@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func attrsMap(attrs []slog.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Key] = a.Value.String()
+	}
+	return m
+}
+
+func TestLogAttrs(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	assert.NotContains(t, attrsMap(LogAttrs(context.Background())), LogKeyTraceID)
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	attrs := attrsMap(LogAttrs(ctx))
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), attrs[LogKeyTraceID])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), attrs[LogKeySpanID])
+}
+
+func TestNewLogHandler(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(buf, nil))).With("component", "test")
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	logger.InfoContext(ctx, "hello")
+	logger.Info("no context")
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "test", records[0]["component"])
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), records[0][LogKeyTraceID])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), records[0][LogKeySpanID])
+	assert.NotContains(t, records[1], LogKeyTraceID)
+}
+
+func TestNewLogHandler_WithGroup(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(buf, nil))).With("component", "test").WithGroup("req").With("id", "1").WithGroup("")
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	logger.InfoContext(ctx, "hello", "method", "GET")
+	logger.Info("no context", "method", "POST")
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "test", records[0]["component"])
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), records[0][LogKeyTraceID])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), records[0][LogKeySpanID])
+	assert.Equal(t, map[string]any{"id": "1", "method": "GET"}, records[0]["req"])
+	assert.NotContains(t, records[1], LogKeyTraceID)
+	assert.Equal(t, map[string]any{"id": "1", "method": "POST"}, records[1]["req"])
+}
+
+func TestNewLogHandler_NestedGroups(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	withGroups := func(h slog.Handler) *slog.Logger {
+		return slog.New(h).With("component", "test").WithGroup("req").With("id", "1").WithGroup("db").With("table", "users")
+	}
+
+	plain, correlated := &bytes.Buffer{}, &bytes.Buffer{}
+	want := withGroups(slog.NewJSONHandler(plain, nil))
+	logger := withGroups(NewLogHandler(slog.NewJSONHandler(correlated, nil)))
+	sibling := logger.With("rows", 2)
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	want.InfoContext(ctx, "query", "op", "select")
+	logger.InfoContext(ctx, "query", "op", "select")
+	sibling.InfoContext(ctx, "query")
+
+	wantRecords := decodeRecords(t, plain)
+	records := decodeRecords(t, correlated)
+	require.Len(t, records, 2)
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), records[0][LogKeyTraceID])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), records[0][LogKeySpanID])
+
+	delete(records[0], LogKeyTraceID)
+	delete(records[0], LogKeySpanID)
+	delete(wantRecords[0], "time")
+	delete(records[0], "time")
+	assert.Equal(t, wantRecords[0], records[0])
+	assert.Equal(t, map[string]any{"id": "1", "db": map[string]any{"table": "users", "rows": float64(2)}}, records[1]["req"])
+}
@@ -0,0 +1,35 @@
+// Package ddzap provides Datadog log correlation helpers for go.uber.org/zap.
+// It lives in its own module so that the tracing module does not depend on zap.
+package ddzap
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+// Fields returns zap fields correlating a log entry with the span in ctx
+// (dd.trace_id, dd.span_id, dd.service, dd.env, dd.version). See tracing.LogAttrs.
+//
+// Example:
+//
+//	logger.Info("user created", ddzap.Fields(ctx)...)
+func Fields(ctx context.Context) []zap.Field {
+	attrs := tracing.LogAttrs(ctx)
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = append(fields, zap.String(a.Key, a.Value.String()))
+	}
+	return fields
+}
+
+// WithContext returns a child of l carrying the correlation fields of ctx.
+//
+// Example:
+//
+//	ddzap.WithContext(ctx, logger).Info("user created")
+func WithContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	return l.With(Fields(ctx)...)
+}
@@ -0,0 +1,38 @@
+package ddzap
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func TestWithContext(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	WithContext(ctx, zap.New(core)).Info("hello")
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), fields["dd.trace_id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), fields["dd.span_id"])
+}
+
+func TestFields_NoSpan(t *testing.T) {
+	for _, f := range Fields(context.Background()) {
+		assert.NotEqual(t, "dd.trace_id", f.Key)
+	}
+}
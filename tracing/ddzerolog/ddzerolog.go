@@ -0,0 +1,45 @@
+// Package ddzerolog provides Datadog log correlation helpers for github.com/rs/zerolog.
+// It lives in its own module so that the tracing module does not depend on zerolog.
+package ddzerolog
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/tuanvm-tyson/ddtrace/tracing"
+)
+
+// Hook is a zerolog.Hook adding the correlation fields of the event's context
+// (set with Event.Ctx or Logger.WithContext) to every event. See tracing.LogAttrs.
+//
+// Example:
+//
+//	logger := zerolog.New(os.Stdout).Hook(ddzerolog.Hook{})
+//	logger.Info().Ctx(ctx).Msg("user created")
+type Hook struct{}
+
+// Run implements zerolog.Hook.
+func (Hook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	ctx := e.GetCtx()
+	if ctx == nil {
+		return
+	}
+	for _, a := range tracing.LogAttrs(ctx) {
+		e.Str(a.Key, a.Value.String())
+	}
+}
+
+// WithContext returns a copy of l carrying the correlation fields of ctx.
+//
+// Example:
+//
+//	l := ddzerolog.WithContext(ctx, logger)
+//	l.Info().Msg("user created")
+func WithContext(ctx context.Context, l zerolog.Logger) zerolog.Logger {
+	zc := l.With()
+	for _, a := range tracing.LogAttrs(ctx) {
+		zc = zc.Str(a.Key, a.Value.String())
+	}
+	return zc.Logger()
+}
@@ -0,0 +1,48 @@
+package ddzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func TestHook(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf).Hook(Hook{})
+	logger.Info().Ctx(ctx).Msg("hello")
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), rec["dd.trace_id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), rec["dd.span_id"])
+}
+
+func TestWithContext(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	buf := &bytes.Buffer{}
+	logger := WithContext(ctx, zerolog.New(buf))
+	logger.Info().Msg("hello")
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), rec["dd.trace_id"])
+}
@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// DisableEnvVar is the environment variable read at startup to disable tracing for
+// specific operations, e.g. DDTRACE_DISABLE="UserRepository.*,OrderService.List".
+const DisableEnvVar = "DDTRACE_DISABLE"
+
+// noopSpan is returned instead of a real span when span creation is skipped.
+var noopSpan, _ = tracer.SpanFromContext(context.Background())
+
+var (
+	disabledMu       sync.Mutex
+	disabledPatterns = map[string]struct{}{}
+	disabledRules    atomic.Pointer[disableRules]
+)
+
+// disableRules is an immutable snapshot of the disabled patterns, swapped atomically
+// so that IsDisabled never takes a lock.
+type disableRules struct {
+	all      bool
+	exact    map[string]struct{}
+	prefixes []string
+}
+
+func init() {
+	if v := os.Getenv(DisableEnvVar); v != "" {
+		Disable(strings.Split(v, ",")...)
+	}
+}
+
+// Disable turns off span creation for all operations matching any of the given patterns.
+// A pattern is either an operation name ("UserRepository.GetUser"), an interface or
+// span prefix ("UserRepository", matching every "UserRepository.<Method>"), a prefix
+// glob ending in "*" ("UserRepository.Get*") or "*" to disable everything.
+//
+// Disabled operations return a no-op span from TracingConfig.StartSpan and StartSpan.
+// It is safe to call Disable at any time, e.g. from an admin endpoint during an incident.
+func Disable(patterns ...string) {
+	disabledMu.Lock()
+	defer disabledMu.Unlock()
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			disabledPatterns[p] = struct{}{}
+		}
+	}
+	rebuildDisableRules()
+}
+
+// Enable removes patterns previously passed to Disable (or set via DDTRACE_DISABLE).
+// Patterns must match exactly the ones that were disabled.
+func Enable(patterns ...string) {
+	disabledMu.Lock()
+	defer disabledMu.Unlock()
+	for _, p := range patterns {
+		delete(disabledPatterns, strings.TrimSpace(p))
+	}
+	rebuildDisableRules()
+}
+
+// EnableAll removes every disabled pattern, restoring tracing for all operations.
+func EnableAll() {
+	disabledMu.Lock()
+	defer disabledMu.Unlock()
+	disabledPatterns = map[string]struct{}{}
+	rebuildDisableRules()
+}
+
+// IsDisabled reports whether span creation is currently disabled for operationName.
+func IsDisabled(operationName string) bool {
+	rules := disabledRules.Load()
+	if rules == nil {
+		return false
+	}
+	if rules.all {
+		return true
+	}
+	if _, ok := rules.exact[operationName]; ok {
+		return true
+	}
+	for _, prefix := range rules.prefixes {
+		if strings.HasPrefix(operationName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuildDisableRules publishes a new snapshot of disabledPatterns. Must be called with disabledMu held.
+func rebuildDisableRules() {
+	if len(disabledPatterns) == 0 {
+		disabledRules.Store(nil)
+		return
+	}
+
+	rules := &disableRules{exact: make(map[string]struct{}, len(disabledPatterns))}
+	for p := range disabledPatterns {
+		switch {
+		case p == "*":
+			rules.all = true
+		case strings.HasSuffix(p, "*"):
+			rules.prefixes = append(rules.prefixes, strings.TrimSuffix(p, "*"))
+		default:
+			rules.exact[p] = struct{}{}
+			rules.prefixes = append(rules.prefixes, p+".")
+		}
+	}
+	disabledRules.Store(rules)
+}
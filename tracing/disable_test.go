@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+func TestIsDisabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		patterns  []string
+		operation string
+		want      bool
+	}{
+		{name: "nothing disabled", operation: "UserRepository.Get", want: false},
+		{name: "exact match", patterns: []string{"UserRepository.Get"}, operation: "UserRepository.Get", want: true},
+		{name: "exact mismatch", patterns: []string{"UserRepository.Get"}, operation: "UserRepository.List", want: false},
+		{name: "interface name", patterns: []string{"UserRepository"}, operation: "UserRepository.List", want: true},
+		{name: "interface name is not a prefix", patterns: []string{"User"}, operation: "UserRepository.List", want: false},
+		{name: "wildcard", patterns: []string{"UserRepository.*"}, operation: "UserRepository.List", want: true},
+		{name: "wildcard other interface", patterns: []string{"UserRepository.*"}, operation: "OrderService.List", want: false},
+		{name: "everything", patterns: []string{"*"}, operation: "OrderService.List", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer EnableAll()
+			Disable(tt.patterns...)
+			assert.Equal(t, tt.want, IsDisabled(tt.operation))
+		})
+	}
+}
+
+func TestEnable(t *testing.T) {
+	defer EnableAll()
+
+	Disable("UserRepository.*", "OrderService")
+	Enable("UserRepository.*")
+
+	assert.False(t, IsDisabled("UserRepository.Get"))
+	assert.True(t, IsDisabled("OrderService.Get"))
+}
+
+func TestTracingConfig_StartSpan_Disabled(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	defer EnableAll()
+
+	Disable("UserRepository.*")
+
+	cfg := NewTracingConfig()
+	ctx := context.Background()
+	span, spanCtx := cfg.StartSpan(ctx, "UserRepository.Get")
+	cfg.FinishSpan(span, nil, nil, nil)
+	assert.Equal(t, ctx, spanCtx)
+
+	span, _ = cfg.StartSpan(ctx, "OrderService.Get")
+	cfg.FinishSpan(span, nil, nil, nil)
+
+	spans := mt.FinishedSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "OrderService.Get", spans[0].OperationName())
+	}
+}
@@ -10,6 +10,8 @@ import (
 var (
 	globalContextDecorator func(ctx context.Context, span ddtrace.Span)
 	globalSpanOpts         []tracer.StartSpanOption
+	globalRequireParent    bool
+	globalMetricsSink      MetricsSink
 )
 
 // SetDefaultContextDecorator sets a context decorator that is automatically applied to ALL spans
@@ -28,3 +30,21 @@ func SetDefaultContextDecorator(f func(ctx context.Context, span ddtrace.Span))
 func SetDefaultSpanOptions(opts ...tracer.StartSpanOption) {
 	globalSpanOpts = opts
 }
+
+// SetDefaultRequireParent controls whether spans are only created when the context already
+// carries an active parent span. When enabled, decorators and manual StartSpan calls made
+// outside of a trace (e.g. background loops) return a no-op span instead of a new root span.
+// Per-instance WithRequireParent and WithoutRequireParent take precedence for decorators.
+//
+// Call this once at application startup, before creating any decorator instances.
+func SetDefaultRequireParent(require bool) {
+	globalRequireParent = require
+}
+
+// SetDefaultMetricsSink sets the sink used by decorators created with WithMetrics
+// (including those generated with "metrics: true" in .ddtrace.yaml).
+//
+// Call this once at application startup, before creating any decorator instances.
+func SetDefaultMetricsSink(sink MetricsSink) {
+	globalMetricsSink = sink
+}
@@ -2,7 +2,10 @@ module github.com/tuanvm-tyson/ddtrace/tracing
 
 go 1.23.0
 
-require gopkg.in/DataDog/dd-trace-go.v1 v1.74.2
+require (
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/DataDog/dd-trace-go.v1 v1.74.2
+)
 
 require (
 	github.com/DataDog/appsec-internal-go v1.11.2 // indirect
@@ -48,7 +51,6 @@ require (
 	github.com/puzpuzpuz/xsync/v3 v3.5.1 // indirect
 	github.com/secure-systems-lab/go-securesystemslib v0.9.0 // indirect
 	github.com/shirou/gopsutil/v4 v4.25.3 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/tinylib/msgp v1.2.5 // indirect
 	github.com/tklauser/go-sysconf v0.3.15 // indirect
 	github.com/tklauser/numcpus v0.10.0 // indirect
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LoggingConfig holds per-instance configuration for generated logging decorators.
+type LoggingConfig struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// LoggingOption configures a LoggingConfig.
+type LoggingOption func(*LoggingConfig)
+
+// NewLoggingConfig creates a LoggingConfig with the given options.
+// By default records are written to slog.Default() at slog.LevelInfo.
+func NewLoggingConfig(opts ...LoggingOption) LoggingConfig {
+	cfg := LoggingConfig{level: slog.LevelInfo}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithLogger sets the logger used by the logging decorator.
+func WithLogger(l *slog.Logger) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.logger = l
+	}
+}
+
+// WithLogLevel sets the level of start and successful finish records.
+// Finish records of calls that returned an error are always logged at slog.LevelError.
+func WithLogLevel(level slog.Level) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.level = level
+	}
+}
+
+func (c *LoggingConfig) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return slog.Default()
+}
+
+// LogStart emits the start record for method and returns the start time to be passed to LogFinish.
+// This method is called by generated decorator code.
+func (c *LoggingConfig) LogStart(ctx context.Context, method string, attrs ...slog.Attr) time.Time {
+	l := c.log()
+	if l.Enabled(ctx, c.level) {
+		all := append(append(LogAttrs(ctx), slog.String("method", method)), attrs...)
+		l.LogAttrs(ctx, c.level, method+" started", all...)
+	}
+	return time.Now()
+}
+
+// LogFinish emits the finish record for method with its duration and error, if any.
+// This method is called by generated decorator code.
+func (c *LoggingConfig) LogFinish(ctx context.Context, method string, start time.Time, err error, attrs ...slog.Attr) {
+	level := c.level
+	if err != nil {
+		level = slog.LevelError
+	}
+	l := c.log()
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	all := append(LogAttrs(ctx), slog.String("method", method), slog.Duration("duration", time.Since(start)))
+	all = append(all, attrs...)
+	if err != nil {
+		all = append(all, slog.String("error", err.Error()))
+	}
+	l.LogAttrs(ctx, level, method+" finished", all...)
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLoggingConfig_StartFinish(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	buf := &bytes.Buffer{}
+	cfg := NewLoggingConfig(WithLogger(slog.New(slog.NewJSONHandler(buf, nil))))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	defer span.Finish()
+
+	start := cfg.LogStart(ctx, "Repo.Get", slog.String("id", "42"))
+	cfg.LogFinish(ctx, "Repo.Get", start, errors.New("boom"), slog.String("id", "42"))
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "INFO", records[0]["level"])
+	assert.Equal(t, "Repo.Get", records[0]["method"])
+	assert.Equal(t, "42", records[0]["id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), records[0]["dd.trace_id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), records[0]["dd.span_id"])
+
+	assert.Equal(t, "ERROR", records[1]["level"])
+	assert.Equal(t, "boom", records[1]["error"])
+	assert.Contains(t, records[1], "duration")
+}
+
+func TestLoggingConfig_Level(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cfg := NewLoggingConfig(
+		WithLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))),
+		WithLogLevel(slog.LevelDebug),
+	)
+
+	ctx := context.Background()
+	cfg.LogFinish(ctx, "Repo.Get", cfg.LogStart(ctx, "Repo.Get"), nil)
+	assert.Empty(t, buf.String())
+
+	cfg.LogFinish(ctx, "Repo.Get", time.Now(), errors.New("boom"))
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.NotContains(t, records[0], "dd.trace_id")
+}
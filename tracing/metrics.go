@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+)
+
+// MetricsSink receives per-operation RED metrics (rate, errors, duration) from
+// tracing decorators. Metrics are recorded for every call of an operation that isn't disabled,
+// independently of trace sampling.
+// Implementations must be safe for concurrent use.
+type MetricsSink interface {
+	// Observe records a single finished call of operation.
+	Observe(operation string, duration time.Duration, err error)
+}
+
+// meteredSpan carries the data needed by FinishSpan to record metrics.
+// It only lives between TracingConfig.StartSpan and TracingConfig.FinishSpan.
+type meteredSpan struct {
+	ddtrace.Span
+	operation string
+	start     time.Time
+}
+
+// StatsdClient is the subset of the DogStatsD client used by DogStatsDSink.
+// *statsd.Client from github.com/DataDog/datadog-go/v5/statsd satisfies it.
+type StatsdClient interface {
+	Count(name string, value int64, tags []string, rate float64) error
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+}
+
+// DogStatsDSink is a MetricsSink reporting through a DogStatsD client.
+// For every call it emits "<prefix>.calls" and, on error, "<prefix>.errors" counters,
+// plus a "<prefix>.duration" timing, all tagged with "operation:<name>".
+type DogStatsDSink struct {
+	client StatsdClient
+	prefix string
+	tags   []string
+}
+
+// NewDogStatsDSink returns a DogStatsDSink. If prefix is empty, "ddtrace" is used.
+// tags are added to every metric.
+func NewDogStatsDSink(client StatsdClient, prefix string, tags ...string) *DogStatsDSink {
+	if prefix == "" {
+		prefix = "ddtrace"
+	}
+	return &DogStatsDSink{
+		client: client,
+		prefix: prefix,
+		tags:   tags,
+	}
+}
+
+// Observe implements MetricsSink.
+func (s *DogStatsDSink) Observe(operation string, duration time.Duration, err error) {
+	tags := make([]string, 0, len(s.tags)+2)
+	tags = append(tags, s.tags...)
+	tags = append(tags, "operation:"+operation, "error:"+strconv.FormatBool(err != nil))
+
+	s.client.Count(s.prefix+".calls", 1, tags, 1) //nolint: errcheck
+	if err != nil {
+		s.client.Count(s.prefix+".errors", 1, tags, 1) //nolint: errcheck
+	}
+	s.client.Timing(s.prefix+".duration", duration, tags, 1) //nolint: errcheck
+}
+
+// OperationStats holds the metrics recorded by MemorySink for a single operation.
+type OperationStats struct {
+	Calls     int64
+	Errors    int64
+	Durations []time.Duration
+}
+
+// MemorySink is an in-memory MetricsSink intended for tests.
+type MemorySink struct {
+	mu    sync.Mutex
+	stats map[string]*OperationStats
+}
+
+// NewMemorySink returns an empty MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{stats: make(map[string]*OperationStats)}
+}
+
+// Observe implements MetricsSink.
+func (s *MemorySink) Observe(operation string, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.stats[operation]
+	if !ok {
+		st = &OperationStats{}
+		s.stats[operation] = st
+	}
+	st.Calls++
+	if err != nil {
+		st.Errors++
+	}
+	st.Durations = append(st.Durations, duration)
+}
+
+// Stats returns a copy of the metrics recorded for operation.
+func (s *MemorySink) Stats(operation string) OperationStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.stats[operation]
+	if !ok {
+		return OperationStats{}
+	}
+	return OperationStats{
+		Calls:     st.Calls,
+		Errors:    st.Errors,
+		Durations: append([]time.Duration(nil), st.Durations...),
+	}
+}
+
+// Reset discards all recorded metrics.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = make(map[string]*OperationStats)
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+func TestTracingConfig_Metrics(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	sink := NewMemorySink()
+	cfg := NewTracingConfig(WithMetricsSink(sink))
+
+	span, _ := cfg.StartSpan(context.Background(), "Repo.Get")
+	cfg.FinishSpan(span, nil, nil, nil)
+	span, _ = cfg.StartSpan(context.Background(), "Repo.Get")
+	cfg.FinishSpan(span, errors.New("boom"), nil, nil)
+
+	stats := sink.Stats("Repo.Get")
+	assert.Equal(t, int64(2), stats.Calls)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Len(t, stats.Durations, 2)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "boom", spans[1].Tag("error.message"))
+}
+
+func TestTracingConfig_Metrics_DisabledSpan(t *testing.T) {
+	defer EnableAll()
+	Disable("Repo.Get")
+
+	sink := NewMemorySink()
+	cfg := NewTracingConfig(WithMetricsSink(sink))
+
+	span, _ := cfg.StartSpan(context.Background(), "Repo.Get")
+	assert.Equal(t, noopSpan, span)
+	cfg.FinishSpan(span, nil, nil, nil)
+	assert.Equal(t, int64(0), sink.Stats("Repo.Get").Calls)
+
+	span, _ = cfg.StartSpan(context.Background(), "Repo.List")
+	cfg.FinishSpan(span, nil, nil, nil)
+	assert.Equal(t, int64(1), sink.Stats("Repo.List").Calls)
+}
+
+func TestWithMetrics_DefaultSink(t *testing.T) {
+	sink := NewMemorySink()
+	SetDefaultMetricsSink(sink)
+	defer SetDefaultMetricsSink(nil)
+
+	withMetrics := NewTracingConfig(WithMetrics())
+	span, _ := withMetrics.StartSpan(context.Background(), "Repo.Get")
+	withMetrics.FinishSpan(span, nil, nil, nil)
+
+	withoutMetrics := NewTracingConfig()
+	span, _ = withoutMetrics.StartSpan(context.Background(), "Repo.List")
+	withoutMetrics.FinishSpan(span, nil, nil, nil)
+
+	assert.Equal(t, int64(1), sink.Stats("Repo.Get").Calls)
+	assert.Equal(t, int64(0), sink.Stats("Repo.List").Calls)
+}
+
+type statsdCall struct {
+	name string
+	tags []string
+}
+
+type fakeStatsd struct {
+	counts  []statsdCall
+	timings []statsdCall
+}
+
+func (f *fakeStatsd) Count(name string, value int64, tags []string, rate float64) error {
+	f.counts = append(f.counts, statsdCall{name: name, tags: tags})
+	return nil
+}
+
+func (f *fakeStatsd) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	f.timings = append(f.timings, statsdCall{name: name, tags: tags})
+	return nil
+}
+
+func TestDogStatsDSink_Observe(t *testing.T) {
+	client := &fakeStatsd{}
+	sink := NewDogStatsDSink(client, "", "env:test")
+
+	sink.Observe("Repo.Get", time.Millisecond, nil)
+	sink.Observe("Repo.Get", time.Millisecond, errors.New("boom"))
+
+	require.Len(t, client.counts, 3)
+	assert.Equal(t, "ddtrace.calls", client.counts[0].name)
+	assert.Equal(t, []string{"env:test", "operation:Repo.Get", "error:false"}, client.counts[0].tags)
+	assert.Equal(t, "ddtrace.errors", client.counts[2].name)
+	require.Len(t, client.timings, 2)
+	assert.Equal(t, "ddtrace.duration", client.timings[0].name)
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// RetryConfig holds per-instance configuration for generated retry decorators.
+type RetryConfig struct {
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+	retryIf     func(err error) bool
+}
+
+// RetryOption configures a RetryConfig.
+type RetryOption func(*RetryConfig)
+
+// NewRetryConfig creates a RetryConfig with the given options.
+// By default a call is attempted up to 3 times with exponential backoff starting at 100ms,
+// and every error except context cancellation and deadline errors is retried.
+func NewRetryConfig(opts ...RetryOption) RetryConfig {
+	cfg := RetryConfig{
+		maxAttempts: 3,
+		backoff:     ExponentialBackoff(100*time.Millisecond, 2*time.Second),
+		retryIf:     isRetryable,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithMaxAttempts sets the maximum number of attempts, including the first call.
+func WithMaxAttempts(n int) RetryOption {
+	return func(c *RetryConfig) {
+		c.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the function returning the delay before the given retry attempt (starting at 1).
+func WithBackoff(f func(attempt int) time.Duration) RetryOption {
+	return func(c *RetryConfig) {
+		c.backoff = f
+	}
+}
+
+// WithRetryIf sets the predicate deciding whether an error is worth retrying.
+func WithRetryIf(f func(err error) bool) RetryOption {
+	return func(c *RetryConfig) {
+		c.retryIf = f
+	}
+}
+
+// ExponentialBackoff returns a backoff function doubling base on every attempt, capped at max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+func isRetryable(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Do calls f until it succeeds, returns a non-retryable error, the attempts are exhausted
+// or ctx is done, and returns the last error. When more than one attempt was made, the
+// "retry.attempts" tag is set on the span in ctx.
+// This method is called by generated decorator code.
+func (c *RetryConfig) Do(ctx context.Context, f func(ctx context.Context) error) error {
+	attempt := 1
+	defer func() {
+		if attempt > 1 {
+			if span, ok := tracer.SpanFromContext(ctx); ok {
+				span.SetTag("retry.attempts", attempt)
+			}
+		}
+	}()
+
+	for {
+		err := f(ctx)
+		if err == nil || attempt >= c.maxAttempts || !c.retryIf(err) {
+			return err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		attempt++
+	}
+}
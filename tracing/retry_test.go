@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestRetryConfig_Do(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name      string
+		errs      []error
+		opts      []RetryOption
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1},
+		{name: "success after retry", errs: []error{errBoom, nil}, wantCalls: 2},
+		{name: "attempts exhausted", errs: []error{errBoom, errBoom, errBoom, nil}, wantErr: errBoom, wantCalls: 3},
+		{name: "not retryable", errs: []error{context.Canceled, nil}, wantErr: context.Canceled, wantCalls: 1},
+		{
+			name:      "custom predicate",
+			errs:      []error{errBoom, nil},
+			opts:      []RetryOption{WithRetryIf(func(error) bool { return false })},
+			wantErr:   errBoom,
+			wantCalls: 1,
+		},
+		{
+			name:      "max attempts",
+			errs:      []error{errBoom, errBoom, errBoom, errBoom, nil},
+			opts:      []RetryOption{WithMaxAttempts(5)},
+			wantCalls: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewRetryConfig(append([]RetryOption{WithBackoff(noBackoff)}, tt.opts...)...)
+			calls := 0
+			err := cfg.Do(context.Background(), func(context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestRetryConfig_Do_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cfg := NewRetryConfig(WithBackoff(func(int) time.Duration { return time.Hour }))
+	calls := 0
+	err := cfg.Do(ctx, func(context.Context) error {
+		calls++
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryConfig_Do_SpanTag(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "Repo.Get")
+	cfg := NewRetryConfig(WithBackoff(noBackoff))
+	calls := 0
+	err := cfg.Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	span.Finish()
+
+	require.NoError(t, err)
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.EqualValues(t, 2, spans[0].Tag("retry.attempts"))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	assert.Equal(t, 100*time.Millisecond, backoff(1))
+	assert.Equal(t, 200*time.Millisecond, backoff(2))
+	assert.Equal(t, 800*time.Millisecond, backoff(4))
+	assert.Equal(t, time.Second, backoff(10))
+}
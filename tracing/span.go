@@ -35,6 +35,8 @@ func WithTracerOptions(opts ...tracer.StartSpanOption) SpanOption {
 // By default, the operation name is auto-detected from the calling function's name
 // using runtime.Caller. Use WithOperationName to override.
 // Global defaults (globalSpanOpts, globalContextDecorator) are applied automatically.
+// If SetDefaultRequireParent(true) was called and ctx has no active span, a no-op span
+// and the original ctx are returned. The same applies to operations disabled via Disable.
 //
 // Example (private function):
 //
@@ -57,6 +59,11 @@ func WithTracerOptions(opts ...tracer.StartSpanOption) SpanOption {
 //
 //	span, ctx := tracing.StartSpan(ctx, tracing.WithOperationName("CustomOp"))
 func StartSpan(ctx context.Context, opts ...SpanOption) (ddtrace.Span, context.Context) {
+	if globalRequireParent {
+		if noop, ok := tracer.SpanFromContext(ctx); !ok {
+			return noop, ctx
+		}
+	}
 	cfg := spanStartConfig{}
 	for _, opt := range opts {
 		opt(&cfg)
@@ -64,6 +71,9 @@ func StartSpan(ctx context.Context, opts ...SpanOption) (ddtrace.Span, context.C
 	if cfg.operationName == "" {
 		cfg.operationName = callerFuncName(1)
 	}
+	if IsDisabled(cfg.operationName) {
+		return noopSpan, ctx
+	}
 	allOpts := append(append([]tracer.StartSpanOption{}, globalSpanOpts...), cfg.tracerOpts...)
 	span, ctx := tracer.StartSpanFromContext(ctx, cfg.operationName, allOpts...)
 	if globalContextDecorator != nil {
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// TimeoutConfig holds per-instance configuration for generated timeout decorators.
+type TimeoutConfig struct {
+	overrides map[string]time.Duration
+}
+
+// TimeoutOption configures a TimeoutConfig.
+type TimeoutOption func(*TimeoutConfig)
+
+// NewTimeoutConfig creates a TimeoutConfig with the given options.
+func NewTimeoutConfig(opts ...TimeoutOption) TimeoutConfig {
+	cfg := TimeoutConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithMethodTimeout overrides the generated timeout of operation (e.g. "UserRepository.Get").
+// A zero or negative duration disables the timeout.
+func WithMethodTimeout(operation string, d time.Duration) TimeoutOption {
+	return func(c *TimeoutConfig) {
+		if c.overrides == nil {
+			c.overrides = make(map[string]time.Duration)
+		}
+		c.overrides[operation] = d
+	}
+}
+
+// WithTimeout derives a context with the timeout of operation, defaulting to d.
+// If ctx carries a span, it is tagged with "timeout.ms" and, when the returned cancel
+// function is called, with "timeout.exceeded" reporting whether this deadline fired.
+// This method is called by generated decorator code.
+func (c *TimeoutConfig) WithTimeout(ctx context.Context, operation string, d time.Duration) (context.Context, context.CancelFunc) {
+	if override, ok := c.overrides[operation]; ok {
+		d = override
+	}
+	if d <= 0 {
+		return ctx, func() {}
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, d)
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return tctx, cancel
+	}
+
+	span.SetTag("timeout.ms", d.Milliseconds())
+	return tctx, func() {
+		span.SetTag("timeout.exceeded", tctx.Err() == context.DeadlineExceeded && ctx.Err() == nil)
+		cancel()
+	}
+}
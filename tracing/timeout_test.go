@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func TestTimeoutConfig_WithTimeout(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		opts         []TimeoutOption
+		wait         time.Duration
+		wantDeadline bool
+		wantExceeded bool
+	}{
+		{name: "no timeout", timeout: 0},
+		{name: "deadline not reached", timeout: time.Minute, wantDeadline: true},
+		{name: "deadline fired", timeout: time.Millisecond, wait: 20 * time.Millisecond, wantDeadline: true, wantExceeded: true},
+		{name: "override", timeout: time.Minute, opts: []TimeoutOption{WithMethodTimeout("Repo.Get", time.Millisecond)}, wait: 20 * time.Millisecond, wantDeadline: true, wantExceeded: true},
+		{name: "override disables", timeout: time.Minute, opts: []TimeoutOption{WithMethodTimeout("Repo.Get", 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt.Reset()
+			cfg := NewTimeoutConfig(tt.opts...)
+
+			span, ctx := tracer.StartSpanFromContext(context.Background(), "Repo.Get")
+			tctx, cancel := cfg.WithTimeout(ctx, "Repo.Get", tt.timeout)
+			time.Sleep(tt.wait)
+			cancel()
+			span.Finish()
+
+			_, hasDeadline := tctx.Deadline()
+			assert.Equal(t, tt.wantDeadline, hasDeadline)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			if tt.wantDeadline {
+				assert.Equal(t, strconv.FormatBool(tt.wantExceeded), spans[0].Tag("timeout.exceeded"))
+				assert.NotNil(t, spans[0].Tag("timeout.ms"))
+			} else {
+				assert.Nil(t, spans[0].Tag("timeout.ms"))
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package tracing
+
+// Unwrapper is implemented by every generated decorator and exposes the value it wraps.
+type Unwrapper interface {
+	Unwrap() any
+}
+
+// Unwrap peels decorator layers off v until it reaches a value of type T,
+// returning false if no layer is a T.
+//
+//	impl, ok := tracing.Unwrap[*service.UserServiceImpl](tracedSvc)
+//
+// Since each decorator implements the interface it wraps, T should be the concrete
+// implementation type; an interface type matches the outermost layer satisfying it.
+func Unwrap[T any](v any) (T, bool) {
+	for v != nil {
+		if t, ok := v.(T); ok {
+			return t, true
+		}
+		u, ok := v.(Unwrapper)
+		if !ok {
+			break
+		}
+		v = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type greeterImpl struct{ name string }
+
+func (g *greeterImpl) Greet() string { return "hello " + g.name }
+
+type greeterDecorator struct {
+	greeter
+}
+
+func (d greeterDecorator) Unwrap() any { return d.greeter }
+
+func TestUnwrap(t *testing.T) {
+	impl := &greeterImpl{name: "gopher"}
+	var wrapped greeter = greeterDecorator{greeterDecorator{impl}}
+
+	tests := []struct {
+		name string
+		v    any
+		ok   bool
+	}{
+		{name: "through decorator layers", v: wrapped, ok: true},
+		{name: "value is already T", v: impl, ok: true},
+		{name: "nil value", v: nil, ok: false},
+		{name: "not an unwrapper", v: "plain", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Unwrap[*greeterImpl](tt.v)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Same(t, impl, got)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
+
+func TestUnwrap_InterfaceMatchesOutermostLayer(t *testing.T) {
+	inner := greeterDecorator{&greeterImpl{}}
+	outer := greeterDecorator{inner}
+
+	got, ok := Unwrap[greeter](outer)
+	assert.True(t, ok)
+	assert.Equal(t, outer, got)
+}